@@ -0,0 +1,169 @@
+package cerrors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	breadcrumbsKey key = 3
+)
+
+const defaultBreadcrumbsCapacity = 32
+
+type Crumb struct {
+	Time     time.Time      `json:"time"`
+	Category string         `json:"category"`
+	Message  string         `json:"message"`
+	Data     map[string]any `json:"data,omitempty"`
+}
+
+// breadcrumbs is a fixed size ring buffer, shared by every context derived
+// from the one it was attached to.
+type breadcrumbs struct {
+	mu    sync.Mutex
+	items []Crumb
+	next  int
+	full  bool
+}
+
+func newBreadcrumbs(capacity int) *breadcrumbs {
+	if capacity <= 0 {
+		capacity = defaultBreadcrumbsCapacity
+	}
+
+	return &breadcrumbs{items: make([]Crumb, capacity)}
+}
+
+func (b *breadcrumbs) add(c Crumb) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.items[b.next] = c
+	b.next = (b.next + 1) % len(b.items)
+	if b.next == 0 {
+		b.full = true
+	}
+}
+
+// snapshot returns a copy of recorded crumbs from oldest to newest.
+func (b *breadcrumbs) snapshot() []Crumb {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var crumbs []Crumb
+	if b.full {
+		crumbs = make([]Crumb, 0, len(b.items))
+		crumbs = append(crumbs, b.items[b.next:]...)
+	}
+	crumbs = append(crumbs, b.items[:b.next]...)
+
+	for i := range crumbs {
+		crumbs[i].Data = copyData(crumbs[i].Data)
+	}
+
+	return crumbs
+}
+
+func copyData(data map[string]any) map[string]any {
+	if data == nil {
+		return nil
+	}
+
+	res := make(map[string]any, len(data))
+	for k, v := range data {
+		res[k] = v
+	}
+
+	return res
+}
+
+// WithBreadcrumbs starts a new breadcrumbs buffer which keeps the last capacity crumbs.
+// Call it once per request so crumbs are not shared between requests.
+func WithBreadcrumbs(ctx context.Context, capacity int) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	return context.WithValue(ctx, breadcrumbsKey, newBreadcrumbs(capacity))
+}
+
+// Breadcrumb records what happened before a possible error into the buffer
+// started by WithBreadcrumbs. Without such a buffer in ctx the crumb is dropped.
+func Breadcrumb(ctx context.Context, category, message string, data map[string]any) {
+	if ctx == nil {
+		return
+	}
+
+	b := getCtxBreadcrumbs(ctx)
+	if b == nil {
+		return
+	}
+
+	b.add(Crumb{Time: configFrom(ctx).now(), Category: category, Message: message, Data: copyData(data)})
+}
+
+func CtxBreadcrumbs(ctx context.Context) []Crumb {
+	if ctx == nil {
+		return nil
+	}
+
+	b := getCtxBreadcrumbs(ctx)
+	if b == nil {
+		return nil
+	}
+
+	return b.snapshot()
+}
+
+func getCtxBreadcrumbs(ctx context.Context) *breadcrumbs {
+	b, ok := ctx.Value(breadcrumbsKey).(*breadcrumbs)
+	if !ok {
+		return nil
+	}
+
+	return b
+}
+
+type withBreadcrumbs interface {
+	error
+	fmt.Formatter
+
+	Breadcrumbs() []Crumb
+}
+
+// check interface implementation
+var _ withBreadcrumbs = (*withBreadcrumbsError)(nil)
+
+type withBreadcrumbsError struct {
+	cause  error
+	crumbs []Crumb
+}
+
+func enrichWithBreadcrumbs(ctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var bErr withBreadcrumbs
+	if errors.As(err, &bErr) {
+		return err
+	}
+
+	crumbs := CtxBreadcrumbs(ctx)
+	if len(crumbs) == 0 {
+		return err
+	}
+
+	return &withBreadcrumbsError{cause: err, crumbs: crumbs}
+}
+
+func (w *withBreadcrumbsError) Error() string        { return w.cause.Error() }
+func (w *withBreadcrumbsError) Unwrap() error        { return w.cause }
+func (w *withBreadcrumbsError) Breadcrumbs() []Crumb { return w.crumbs }
+func (w *withBreadcrumbsError) Format(f fmt.State, verb rune) {
+	fmt.Fprintf(f, fmt.FormatString(f, verb), w.cause)
+}
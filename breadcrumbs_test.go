@@ -0,0 +1,158 @@
+package cerrors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestBreadcrumb(t *testing.T) {
+	t.Run("without ctx", func(t *testing.T) {
+		crumbs := CtxBreadcrumbs(nil)
+		if crumbs != nil {
+			t.Error("not nil breadcrumbs")
+		}
+	})
+
+	t.Run("context without breadcrumbs", func(t *testing.T) {
+		crumbs := CtxBreadcrumbs(context.Background())
+		if crumbs != nil {
+			t.Error("not nil breadcrumbs")
+		}
+	})
+
+	t.Run("recorded in order", func(t *testing.T) {
+		ctx := WithBreadcrumbs(context.Background(), 10)
+		Breadcrumb(ctx, "cache", "cache miss", nil)
+		Breadcrumb(ctx, "db", "db query", map[string]any{"table": "users"})
+
+		requireCrumbMessages(t, CtxBreadcrumbs(ctx), []string{"cache miss", "db query"})
+
+		crumb := CtxBreadcrumbs(ctx)[1]
+		if crumb.Category != "db" || crumb.Time.IsZero() {
+			t.Errorf("unexpected crumb: %+v", crumb)
+		}
+
+		expectedData := map[string]any{"table": "users"}
+		if !reflect.DeepEqual(expectedData, crumb.Data) {
+			t.Errorf("unexpected data: expected %v, got %v", expectedData, crumb.Data)
+		}
+	})
+
+	t.Run("bounded", func(t *testing.T) {
+		ctx := WithBreadcrumbs(context.Background(), 2)
+		Breadcrumb(ctx, "http", "retry 1", nil)
+		Breadcrumb(ctx, "http", "retry 2", nil)
+		Breadcrumb(ctx, "http", "retry 3", nil)
+
+		requireCrumbMessages(t, CtxBreadcrumbs(ctx), []string{"retry 2", "retry 3"})
+	})
+
+	t.Run("without buffer", func(t *testing.T) {
+		ctx := context.Background()
+		Breadcrumb(ctx, "app", "started", nil)
+
+		if crumbs := CtxBreadcrumbs(ctx); crumbs != nil {
+			t.Errorf("unexpected breadcrumbs: %v", crumbs)
+		}
+	})
+
+	t.Run("data copied", func(t *testing.T) {
+		ctx := WithBreadcrumbs(context.Background(), 10)
+		data := map[string]any{"table": "users"}
+		Breadcrumb(ctx, "db", "db query", data)
+		data["table"] = "orders"
+
+		crumbs := CtxBreadcrumbs(ctx)
+		crumbs[0].Data["table"] = "items"
+
+		expectedData := map[string]any{"table": "users"}
+		if !reflect.DeepEqual(expectedData, CtxBreadcrumbs(ctx)[0].Data) {
+			t.Errorf("unexpected data: expected %v, got %v", expectedData, CtxBreadcrumbs(ctx)[0].Data)
+		}
+	})
+
+	t.Run("not shared between requests", func(t *testing.T) {
+		base := WithBreadcrumbs(context.Background(), 10)
+		Breadcrumb(base, "app", "started", nil)
+
+		request1 := WithBreadcrumbs(base, 10)
+		Breadcrumb(request1, "http", "request 1", nil)
+
+		request2 := WithBreadcrumbs(base, 10)
+		Breadcrumb(request2, "http", "request 2", nil)
+
+		requireCrumbMessages(t, CtxBreadcrumbs(request1), []string{"request 1"})
+		requireCrumbMessages(t, CtxBreadcrumbs(request2), []string{"request 2"})
+	})
+
+	t.Run("concurrent", func(t *testing.T) {
+		ctx := WithBreadcrumbs(context.Background(), 10)
+
+		wg := sync.WaitGroup{}
+		for i := 0; i < 100; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				Breadcrumb(ctx, "worker", fmt.Sprint(i), nil)
+			}(i)
+		}
+		wg.Wait()
+
+		if len(CtxBreadcrumbs(ctx)) != 10 {
+			t.Errorf("unexpected breadcrumbs count: expected %d, got %d", 10, len(CtxBreadcrumbs(ctx)))
+		}
+	})
+}
+
+func TestBreadcrumbs(t *testing.T) {
+	t.Run("nil", func(t *testing.T) {
+		crumbs := Breadcrumbs(nil)
+		if crumbs != nil {
+			t.Error("not nil breadcrumbs")
+		}
+	})
+
+	t.Run("snapshot on enrich", func(t *testing.T) {
+		ctx := WithBreadcrumbs(context.Background(), 10)
+		Breadcrumb(ctx, "cache", "cache miss", nil)
+
+		err := Enrich(ctx, errors.New("err"))
+		Breadcrumb(ctx, "db", "db query", nil)
+
+		requireCrumbMessages(t, Breadcrumbs(err), []string{"cache miss"})
+	})
+
+	t.Run("several calls - errors wrap not grow", func(t *testing.T) {
+		ctx := WithBreadcrumbs(context.Background(), 10)
+		Breadcrumb(ctx, "cache", "cache miss", nil)
+
+		err := Enrich(ctx, errors.New("err"))
+		err = Enrich(ctx, err)
+
+		wrapsCount := 0
+		for err := err; err != nil; err = errors.Unwrap(err) {
+			wrapsCount++
+		}
+
+		// original, withStack, withBreadcrumbs
+		expected := 3
+		if wrapsCount != expected {
+			t.Errorf("unexpected wraps: expected %d, got %d", expected, wrapsCount)
+		}
+	})
+}
+
+func requireCrumbMessages(t *testing.T, crumbs []Crumb, expected []string) {
+	messages := make([]string, 0, len(crumbs))
+	for _, c := range crumbs {
+		messages = append(messages, c.Message)
+	}
+
+	if !reflect.DeepEqual(expected, messages) {
+		t.Errorf("unexpected breadcrumbs: expected %v, got %v", expected, messages)
+	}
+}
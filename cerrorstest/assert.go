@@ -0,0 +1,170 @@
+// Package cerrorstest provides assertions for errors built with cerrors and
+// a Reporter which records reports for inspection.
+package cerrorstest
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/sloory/cerrors"
+)
+
+func AssertHasField(t testing.TB, err error, key string, value any) {
+	t.Helper()
+
+	fields := cerrors.Fields(err)
+	got, ok := fields[key]
+	if !ok {
+		t.Errorf("field %q not found in %s", key, formatFields(fields))
+		return
+	}
+
+	if !reflect.DeepEqual(value, got) {
+		t.Errorf("unexpected field %q:\n%s", key, diff(
+			[]string{fmt.Sprintf("%#v", value)},
+			[]string{fmt.Sprintf("%#v", got)},
+		))
+	}
+}
+
+func AssertComponents(t testing.TB, err error, components ...string) {
+	t.Helper()
+
+	got := cerrors.Components(err)
+	if !reflect.DeepEqual(components, got) && (len(components) != 0 || len(got) != 0) {
+		t.Errorf("unexpected components:\n%s", diff(components, got))
+	}
+}
+
+func AssertCode(t testing.TB, err error, code string) {
+	t.Helper()
+
+	if got := cerrors.Code(err); got != code {
+		t.Errorf("unexpected code:\n%s", diff([]string{code}, []string{got}))
+	}
+}
+
+// AssertOpaque checks that err shows only publicMsg and hides the message of its cause.
+func AssertOpaque(t testing.TB, err error, publicMsg string) {
+	t.Helper()
+
+	if err == nil {
+		t.Errorf("expected opaque error %q, got nil", publicMsg)
+		return
+	}
+
+	if err.Error() != publicMsg {
+		t.Errorf("unexpected public message:\n%s", diff([]string{publicMsg}, []string{err.Error()}))
+		return
+	}
+
+	var oErr interface {
+		Opaque() bool
+		Unwrap() error
+	}
+	if !errors.As(err, &oErr) || !oErr.Opaque() {
+		t.Errorf("error %q is not opaque", publicMsg)
+		return
+	}
+
+	if cause := oErr.Unwrap().Error(); cause != "" && strings.Contains(err.Error(), cause) {
+		t.Errorf("error %q does not hide its cause", publicMsg)
+	}
+}
+
+// AssertStackContains checks that the stack of err has a frame of funcName,
+// either fully qualified or with the package path left out.
+func AssertStackContains(t testing.TB, err error, funcName string) {
+	t.Helper()
+
+	stack := cerrors.Stack(err)
+	if len(stack) == 0 {
+		t.Errorf("error %q has no stack", err)
+		return
+	}
+
+	var functions []string
+	for _, f := range stack {
+		name := f.Function()
+		if name == funcName || strings.HasSuffix(name, "/"+funcName) {
+			return
+		}
+		functions = append(functions, name)
+	}
+
+	t.Errorf("function %s not found in stack:\n\t%s", funcName, strings.Join(functions, "\n\t"))
+}
+
+// AssertChain checks that the unwrap chain of err contains errors of the types of
+// the given values in the same order, other errors may appear in between. Errors
+// joined with errors.Join are walked depth first:
+//
+//	AssertChain(t, err, (*os.PathError)(nil), syscall.Errno(0))
+func AssertChain(t testing.TB, err error, types ...any) {
+	t.Helper()
+
+	expected := make([]string, 0, len(types))
+	for i, typ := range types {
+		if typ == nil {
+			t.Fatalf("type %d is nil, pass a typed value like (*os.PathError)(nil)", i)
+		}
+		expected = append(expected, reflect.TypeOf(typ).String())
+	}
+
+	var chain []string
+	i := 0
+	walkChain(err, func(e error) {
+		chain = append(chain, reflect.TypeOf(e).String())
+		if i < len(types) && reflect.TypeOf(e) == reflect.TypeOf(types[i]) {
+			i++
+		}
+	})
+
+	if i < len(types) {
+		t.Errorf("unexpected error chain:\n%s", diff(expected, chain))
+	}
+}
+
+// walkChain calls f for err and every error it wraps, depth first.
+func walkChain(err error, f func(error)) {
+	for err != nil {
+		f(err)
+
+		switch e := err.(type) {
+		case interface{ Unwrap() error }:
+			err = e.Unwrap()
+		case interface{ Unwrap() []error }:
+			for _, joined := range e.Unwrap() {
+				walkChain(joined, f)
+			}
+			return
+		default:
+			return
+		}
+	}
+}
+
+func formatFields(fields map[string]any) string {
+	if len(fields) == 0 {
+		return "no fields"
+	}
+
+	return fmt.Sprint(fields)
+}
+
+// diff renders all expected lines prefixed with - followed by all got lines
+// prefixed with +.
+func diff(expected, got []string) string {
+	var b strings.Builder
+	for _, line := range expected {
+		b.WriteString("\t- " + line + "\n")
+	}
+	for _, line := range got {
+		b.WriteString("\t+ " + line + "\n")
+	}
+
+	return strings.TrimSuffix(b.String(), "\n")
+}
@@ -0,0 +1,151 @@
+package cerrorstest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"runtime"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/sloory/cerrors"
+)
+
+// recordingT records failures instead of failing the test.
+type recordingT struct {
+	testing.TB
+	failures []string
+}
+
+func (r *recordingT) Helper() {}
+
+func (r *recordingT) Errorf(format string, args ...any) {
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
+
+func (r *recordingT) Fatalf(format string, args ...any) {
+	r.Errorf(format, args...)
+	runtime.Goexit()
+}
+
+// run calls assert in its own goroutine, so that Fatalf stops only the assertion.
+func (r *recordingT) run(assert func(t testing.TB)) {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		assert(r)
+	}()
+	<-done
+}
+
+func requireFailure(t *testing.T, assert func(t testing.TB), contains string) {
+	t.Helper()
+
+	rt := &recordingT{TB: t}
+	rt.run(assert)
+
+	if len(rt.failures) == 0 {
+		t.Fatal("expect failure")
+	}
+
+	if !strings.Contains(rt.failures[0], contains) {
+		t.Errorf("expect %q in failure:\n%s", contains, rt.failures[0])
+	}
+}
+
+func requireSuccess(t *testing.T, assert func(t testing.TB)) {
+	t.Helper()
+
+	rt := &recordingT{TB: t}
+	rt.run(assert)
+
+	if len(rt.failures) != 0 {
+		t.Errorf("unexpected failures: %v", rt.failures)
+	}
+}
+
+func TestAssertHasField(t *testing.T) {
+	err := cerrors.WithField(errors.New("err"), "userId", 11)
+
+	requireSuccess(t, func(t testing.TB) { AssertHasField(t, err, "userId", 11) })
+	requireFailure(t, func(t testing.TB) { AssertHasField(t, err, "userId", "11") }, "- \"11\"\n\t+ 11")
+	requireFailure(t, func(t testing.TB) { AssertHasField(t, err, "requestId", 1) }, `field "requestId" not found`)
+}
+
+func TestAssertComponents(t *testing.T) {
+	ctx := cerrors.InComponent(context.Background(), "api")
+	err := cerrors.Enrich(cerrors.InComponent(ctx, "storage"), errors.New("err"))
+
+	requireSuccess(t, func(t testing.TB) { AssertComponents(t, err, "api", "storage") })
+	requireSuccess(t, func(t testing.TB) { AssertComponents(t, errors.New("err")) })
+	requireFailure(t, func(t testing.TB) { AssertComponents(t, err, "api") }, "- api\n\t+ api\n\t+ storage")
+}
+
+func TestAssertCode(t *testing.T) {
+	err := cerrors.WithCode(errors.New("err"), "user.not_found")
+
+	requireSuccess(t, func(t testing.TB) { AssertCode(t, err, "user.not_found") })
+	requireFailure(t, func(t testing.TB) { AssertCode(t, err, "user.exists") }, "- user.exists\n\t+ user.not_found")
+}
+
+func TestAssertOpaque(t *testing.T) {
+	err := cerrors.Opaque("internal error", errors.New("connection refused"))
+
+	requireSuccess(t, func(t testing.TB) { AssertOpaque(t, err, "internal error") })
+	requireSuccess(t, func(t testing.TB) {
+		AssertOpaque(t, cerrors.Enrich(context.Background(), err), "internal error")
+	})
+	requireFailure(t, func(t testing.TB) { AssertOpaque(t, err, "not found") }, "- not found\n\t+ internal error")
+	requireFailure(t, func(t testing.TB) {
+		AssertOpaque(t, cerrors.Wrap("internal error", errors.New("connection refused")), "internal error: connection refused")
+	}, "is not opaque")
+	requireFailure(t, func(t testing.TB) {
+		AssertOpaque(t, cerrors.Opaque("connection refused", errors.New("connection refused")), "connection refused")
+	}, "does not hide its cause")
+}
+
+func TestAssertStackContains(t *testing.T) {
+	err := cerrors.WithStack(errors.New("err"))
+
+	requireSuccess(t, func(t testing.TB) { AssertStackContains(t, err, "cerrorstest.TestAssertStackContains") })
+	requireSuccess(t, func(t testing.TB) {
+		AssertStackContains(t, err, "github.com/sloory/cerrors/cerrorstest.TestAssertStackContains")
+	})
+	requireFailure(t, func(t testing.TB) { AssertStackContains(t, err, "main.main") }, "function main.main not found in stack")
+	requireFailure(t, func(t testing.TB) { AssertStackContains(t, errors.New("err"), "main.main") }, "has no stack")
+}
+
+func TestAssertChain(t *testing.T) {
+	_, openErr := os.Open("not existing file")
+	err := cerrors.Wrap("read config", cerrors.WithStack(openErr))
+
+	requireSuccess(t, func(t testing.TB) { AssertChain(t, err, (*fs.PathError)(nil)) })
+	requireSuccess(t, func(t testing.TB) { AssertChain(t, err, (*fs.PathError)(nil), syscall.Errno(0)) })
+	requireFailure(t, func(t testing.TB) {
+		AssertChain(t, err, (*fs.PathError)(nil), (*fs.PathError)(nil))
+	}, "- *fs.PathError\n\t- *fs.PathError\n\t+ *fmt.wrapError")
+	requireFailure(t, func(t testing.TB) { AssertChain(t, err, nil) }, "type 0 is nil")
+
+	joined := errors.Join(errors.New("other"), err)
+	requireSuccess(t, func(t testing.TB) { AssertChain(t, joined, (*fs.PathError)(nil), syscall.Errno(0)) })
+}
+
+func TestReporter(t *testing.T) {
+	r := &Reporter{}
+	ctx := context.Background()
+	err := errors.New("err")
+
+	r.Report(ctx, err)
+
+	if len(r.Reports()) != 1 || r.Reports()[0].Ctx != ctx || r.Errors()[0] != err {
+		t.Errorf("unexpected reports: %v", r.Reports())
+	}
+
+	r.Reset()
+	if len(r.Reports()) != 0 {
+		t.Errorf("unexpected reports: %v", r.Reports())
+	}
+}
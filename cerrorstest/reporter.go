@@ -0,0 +1,55 @@
+package cerrorstest
+
+import (
+	"context"
+	"sync"
+
+	"github.com/sloory/cerrors"
+)
+
+type Report struct {
+	Ctx context.Context
+	Err error
+}
+
+// Reporter is a fake cerrors.Reporter which keeps every report in memory.
+type Reporter struct {
+	mu      sync.Mutex
+	reports []Report
+}
+
+// check interface implementation
+var _ cerrors.Reporter = (*Reporter)(nil)
+
+func (r *Reporter) Report(ctx context.Context, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.reports = append(r.reports, Report{Ctx: ctx, Err: err})
+}
+
+func (r *Reporter) Reports() []Report {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]Report(nil), r.reports...)
+}
+
+func (r *Reporter) Errors() []error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	errs := make([]error, 0, len(r.reports))
+	for _, report := range r.reports {
+		errs = append(errs, report.Err)
+	}
+
+	return errs
+}
+
+func (r *Reporter) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.reports = nil
+}
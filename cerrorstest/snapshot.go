@@ -0,0 +1,244 @@
+package cerrorstest
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sloory/cerrors"
+	"github.com/sloory/cerrors/internal/stdlib"
+)
+
+// UpdateEnv is the environment variable which makes Snapshot rewrite golden files
+// when it is set to a non-empty value.
+const UpdateEnv = "CERRORSTEST_UPDATE"
+
+type snapshotOptions struct {
+	json      bool
+	keepLines bool
+}
+
+type SnapshotOption func(*snapshotOptions)
+
+// JSON renders the snapshot with cerrors.MarshalJSON instead of %+v.
+func JSON() SnapshotOption {
+	return func(o *snapshotOptions) { o.json = true }
+}
+
+// KeepLines leaves line numbers of frames in the snapshot.
+func KeepLines() SnapshotOption {
+	return func(o *snapshotOptions) { o.keepLines = true }
+}
+
+// Snapshot compares err rendered with %+v against testdata/<test name>.golden.
+// Machine specific values are normalized: paths become relative to the module
+// root, standard library frames are dropped, PCs, goroutine ids, pids, hosts
+// and times are replaced by placeholders, and so are line numbers unless
+// KeepLines is given. Run tests with CERRORSTEST_UPDATE=1 to rewrite golden
+// files, or with -update if the test package defines such a boolean flag.
+func Snapshot(t testing.TB, err error, opts ...SnapshotOption) {
+	t.Helper()
+
+	o := snapshotOptions{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if o.json {
+		compareGolden(t, renderJSON(t, err, o))
+		return
+	}
+
+	compareGolden(t, []byte(normalizeText(fmt.Sprintf("%+v", err), o)+"\n"))
+}
+
+// SnapshotText is Snapshot for text rendered by the caller, such as a formatted
+// stack trace or frame. The JSON option is ignored.
+func SnapshotText(t testing.TB, text string, opts ...SnapshotOption) {
+	t.Helper()
+
+	o := snapshotOptions{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	compareGolden(t, []byte(normalizeText(text, o)+"\n"))
+}
+
+func compareGolden(t testing.TB, got []byte) {
+	t.Helper()
+
+	golden := filepath.Join("testdata", strings.ReplaceAll(t.Name(), "/", "_")+".golden")
+	if updateGolden() {
+		if err := os.MkdirAll("testdata", 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(golden, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	expected, readErr := os.ReadFile(golden)
+	if readErr != nil {
+		t.Fatalf("%v, run test with %s=1 to create it", readErr, UpdateEnv)
+	}
+
+	if !bytes.Equal(expected, got) {
+		t.Errorf("snapshot %s does not match:\n%s", golden, diff(
+			strings.Split(strings.TrimSuffix(string(expected), "\n"), "\n"),
+			strings.Split(strings.TrimSuffix(string(got), "\n"), "\n"),
+		))
+	}
+}
+
+// updateGolden reports whether golden files are rewritten, with UpdateEnv or
+// with an -update flag of the test package. The flag is looked up instead of
+// registered, so packages defining their own -update do not clash with it.
+func updateGolden() bool {
+	if os.Getenv(UpdateEnv) != "" {
+		return true
+	}
+
+	f := flag.Lookup("update")
+	if f == nil {
+		return false
+	}
+
+	getter, ok := f.Value.(flag.Getter)
+	if !ok {
+		return false
+	}
+
+	on, _ := getter.Get().(bool)
+	return on
+}
+
+var (
+	pcRe         = regexp.MustCompile(`0x[0-9a-f]+`)
+	frameFileRe  = regexp.MustCompile(`^\t(.+\.(?:go|s))(?::(\d+))?$`)
+	originLineRe = regexp.MustCompile(`^(time|host|pid|goroutine|revision): .*$`)
+)
+
+func normalizeText(s string, o snapshotOptions) string {
+	root := moduleRoot()
+
+	lines := strings.Split(s, "\n")
+	out := make([]string, 0, len(lines))
+	for i, line := range lines {
+		if m := frameFileRe.FindStringSubmatch(line); m != nil {
+			// the function of the frame is on the line before, drop it too
+			if i > 0 && stdlib.Contains(lines[i-1]) {
+				if len(out) > 0 {
+					out = out[:len(out)-1]
+				}
+				continue
+			}
+
+			file := "\t" + relative(m[1], root)
+			switch {
+			case m[2] == "":
+			case o.keepLines:
+				file += ":" + m[2]
+			default:
+				file += ":LINE"
+			}
+			out = append(out, file)
+			continue
+		}
+
+		line = originLineRe.ReplaceAllString(line, "$1: X")
+		out = append(out, pcRe.ReplaceAllString(line, "0xPC"))
+	}
+
+	return strings.Join(out, "\n")
+}
+
+func renderJSON(t testing.TB, err error, o snapshotOptions) []byte {
+	t.Helper()
+
+	d := cerrors.Describe(err)
+	if d != nil {
+		root := moduleRoot()
+
+		d.Stack = normalizeFrames(d.Stack, root, o)
+		for i, stack := range d.Rethrown {
+			d.Rethrown[i] = normalizeFrames(stack, root, o)
+		}
+
+		if d.Origin != nil {
+			d.Origin = &cerrors.Origin{Host: "X"}
+		}
+		// the crumbs are shared with err
+		d.Breadcrumbs = append([]cerrors.Crumb(nil), d.Breadcrumbs...)
+		for i := range d.Breadcrumbs {
+			d.Breadcrumbs[i].Time = time.Time{}
+		}
+	}
+
+	b, mErr := json.MarshalIndent(d, "", "  ")
+	if mErr != nil {
+		t.Fatal(mErr)
+	}
+
+	return append(b, '\n')
+}
+
+// normalizeFrames drops standard library frames and makes the others
+// independent of the machine, like normalizeText does.
+func normalizeFrames(frames []cerrors.FrameDetails, root string, o snapshotOptions) []cerrors.FrameDetails {
+	out := frames[:0]
+	for _, f := range frames {
+		if stdlib.Contains(f.Function) {
+			continue
+		}
+
+		f.File = relative(f.File, root)
+		if !o.keepLines {
+			f.Line = 0
+		}
+		out = append(out, f)
+	}
+
+	return out
+}
+
+func relative(file, root string) string {
+	if root != "" && strings.HasPrefix(file, root+"/") {
+		return strings.TrimPrefix(file, root+"/")
+	}
+
+	if i := strings.Index(file, "/pkg/mod/"); i >= 0 {
+		return "$GOMODCACHE/" + file[i+len("/pkg/mod/"):]
+	}
+
+	return file
+}
+
+// moduleRoot returns the closest directory with go.mod, starting from the
+// working directory of the test.
+func moduleRoot() string {
+	dir, err := os.Getwd()
+	if err != nil {
+		return ""
+	}
+
+	for {
+		if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil {
+			return filepath.ToSlash(dir)
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
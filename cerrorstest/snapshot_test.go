@@ -0,0 +1,127 @@
+package cerrorstest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/sloory/cerrors"
+)
+
+func TestNormalizeText(t *testing.T) {
+	root := moduleRoot()
+	input := "err\n" +
+		"github.com/sloory/cerrors/cerrorstest.TestNormalizeText\n" +
+		"\t" + root + "/cerrorstest/snapshot_test.go:12\n" +
+		"github.com/other/lib.Func\n" +
+		"\t/home/user/go/pkg/mod/github.com/other/lib@v1.0.0/lib.go:7\n" +
+		"testing.tRunner\n" +
+		"\t" + runtime.GOROOT() + "/src/testing/testing.go:1595\n" +
+		"runtime.goexit\n" +
+		"\t" + runtime.GOROOT() + "/src/runtime/asm_amd64.s:1650\n" +
+		"net/http.(*conn).serve\n" +
+		"\tnet/http/server.go:2039\n" +
+		"time: 2023-12-10T10:00:00Z\n" +
+		"goroutine: 18\n" +
+		"pc: 0x4a5b3c"
+
+	t.Run("without lines", func(t *testing.T) {
+		expected := "err\n" +
+			"github.com/sloory/cerrors/cerrorstest.TestNormalizeText\n" +
+			"\tcerrorstest/snapshot_test.go:LINE\n" +
+			"github.com/other/lib.Func\n" +
+			"\t$GOMODCACHE/github.com/other/lib@v1.0.0/lib.go:LINE\n" +
+			"time: X\n" +
+			"goroutine: X\n" +
+			"pc: 0xPC"
+
+		if got := normalizeText(input, snapshotOptions{}); got != expected {
+			t.Errorf("unexpected text:\n%s", diff([]string{expected}, []string{got}))
+		}
+	})
+
+	t.Run("keep lines", func(t *testing.T) {
+		got := normalizeText(input, snapshotOptions{keepLines: true})
+
+		expected := "err\n" +
+			"github.com/sloory/cerrors/cerrorstest.TestNormalizeText\n" +
+			"\tcerrorstest/snapshot_test.go:12\n"
+		if got[:len(expected)] != expected {
+			t.Errorf("unexpected text:\n%s", diff([]string{expected}, []string{got}))
+		}
+	})
+
+	t.Run("file without line", func(t *testing.T) {
+		got := normalizeText("\t"+root+"/cerrorstest/snapshot_test.go", snapshotOptions{})
+
+		expected := "\tcerrorstest/snapshot_test.go"
+		if got != expected {
+			t.Errorf("unexpected text:\n%s", diff([]string{expected}, []string{got}))
+		}
+	})
+}
+
+func TestRenderJSON(t *testing.T) {
+	t.Run("breadcrumbs of error unchanged", func(t *testing.T) {
+		ctx := cerrors.WithBreadcrumbs(context.Background(), 10)
+		cerrors.Breadcrumb(ctx, "db", "db query", nil)
+		err := cerrors.Enrich(ctx, errors.New("err"))
+
+		renderJSON(t, err, snapshotOptions{})
+
+		if cerrors.Breadcrumbs(err)[0].Time.IsZero() {
+			t.Error("breadcrumb time of error changed")
+		}
+	})
+
+	t.Run("rethrown stacks normalized", func(t *testing.T) {
+		ctx := cerrors.WithConfig(context.Background(), cerrors.Config{RethrowStacks: true})
+		errs := make(chan error)
+		go func() {
+			errs <- cerrors.Enrich(ctx, errors.New("err"))
+		}()
+		err := cerrors.Enrich(ctx, <-errs)
+
+		var d cerrors.Details
+		if jErr := json.Unmarshal(renderJSON(t, err, snapshotOptions{}), &d); jErr != nil {
+			t.Fatal(jErr)
+		}
+
+		if len(d.Rethrown) != 1 {
+			t.Fatalf("unexpected rethrown stacks count: expected %d, got %d", 1, len(d.Rethrown))
+		}
+		for _, f := range d.Rethrown[0] {
+			if f.Line != 0 || strings.HasPrefix(f.File, "/") {
+				t.Errorf("unexpected rethrown frame: %+v", f)
+			}
+		}
+	})
+}
+
+// updateFlag is defined like consumers of Snapshot do for their own golden
+// files, cerrorstest must not register a clashing one.
+var updateFlag = flag.Bool("update", false, "rewrite golden files")
+
+func TestUpdateGolden(t *testing.T) {
+	t.Setenv(UpdateEnv, "")
+	if updateGolden() {
+		t.Error("unexpected update without env and flag")
+	}
+
+	t.Setenv(UpdateEnv, "1")
+	if !updateGolden() {
+		t.Error("expect update with env")
+	}
+
+	t.Setenv(UpdateEnv, "")
+	*updateFlag = true
+	defer func() { *updateFlag = false }()
+
+	if !updateGolden() {
+		t.Error("expect update with flag")
+	}
+}
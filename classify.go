@@ -0,0 +1,106 @@
+package cerrors
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"syscall"
+)
+
+type classification struct {
+	kind      Kind
+	retryable bool
+}
+
+// Classify returns err with the kind and the retryable mark of the standard library
+// error in its chain: context, os, io, net and syscall errors. Errors which already
+// have a kind or are not recognized are returned as is.
+func Classify(err error) error {
+	if err == nil || KindOf(err) != KindUnknown {
+		return err
+	}
+
+	c, ok := classify(err)
+	if !ok {
+		return err
+	}
+
+	if c.retryable {
+		err = WithRetryable(err, true)
+	}
+	return WithKind(err, c.kind)
+}
+
+// ClassifyHook is an EnrichHook which classifies errors inside Enrich, see Classify.
+func ClassifyHook(_ context.Context, e *Enrichment) {
+	e.Err = Classify(e.Err)
+}
+
+// errnoClassifications is checked before net.Error, so the cause of a network error wins
+// over its generic timeout or temporary state.
+var errnoClassifications = []struct {
+	errno syscall.Errno
+	classification
+}{
+	{syscall.ECONNREFUSED, classification{KindUnavailable, true}},
+	{syscall.ECONNRESET, classification{KindUnavailable, true}},
+	{syscall.ECONNABORTED, classification{KindUnavailable, true}},
+	{syscall.EPIPE, classification{KindUnavailable, true}},
+	{syscall.EHOSTUNREACH, classification{KindUnavailable, true}},
+	{syscall.ENETUNREACH, classification{KindUnavailable, true}},
+	{syscall.ETIMEDOUT, classification{KindTimeout, true}},
+}
+
+func classify(err error) (classification, bool) {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, os.ErrDeadlineExceeded):
+		return classification{KindTimeout, true}, true
+	case errors.Is(err, context.Canceled):
+		return classification{kind: KindCanceled}, true
+	}
+
+	for _, e := range errnoClassifications {
+		if errors.Is(err, e.errno) {
+			return e.classification, true
+		}
+	}
+
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		switch {
+		case dnsErr.IsNotFound:
+			return classification{kind: KindNotFound}, true
+		case dnsErr.IsTimeout:
+			return classification{KindTimeout, true}, true
+		default:
+			return classification{KindUnavailable, dnsErr.IsTemporary}, true
+		}
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return classification{KindTimeout, true}, true
+	}
+
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		return classification{kind: KindNotFound}, true
+	case errors.Is(err, os.ErrPermission):
+		return classification{kind: KindPermission}, true
+	case errors.Is(err, os.ErrExist):
+		return classification{kind: KindConflict}, true
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return classification{KindUnavailable, true}, true
+	case errors.Is(err, net.ErrClosed):
+		return classification{kind: KindUnavailable}, true
+	}
+
+	var opErr *net.OpError
+	if errors.As(err, &opErr) {
+		return classification{kind: KindUnavailable}, true
+	}
+
+	return classification{}, false
+}
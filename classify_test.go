@@ -0,0 +1,185 @@
+package cerrors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func requireClassified(t *testing.T, err error, kind Kind, retryable bool) {
+	t.Helper()
+
+	classified := Classify(err)
+	if !errors.Is(classified, err) {
+		t.Errorf("lost original error: %v", classified)
+	}
+	if KindOf(classified) != kind {
+		t.Errorf("unexpected kind of %v: expected %v, got %v", err, kind, KindOf(classified))
+	}
+	if IsRetryable(classified) != retryable {
+		t.Errorf("unexpected retryable of %v: expected %v, got %v", err, retryable, IsRetryable(classified))
+	}
+}
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		kind      Kind
+		retryable bool
+	}{
+		{"deadline", fmt.Errorf("query: %w", context.DeadlineExceeded), KindTimeout, true},
+		{"canceled", context.Canceled, KindCanceled, false},
+		{"not exist", &fs.PathError{Op: "open", Path: "/missing", Err: syscall.ENOENT}, KindNotFound, false},
+		{"permission", &fs.PathError{Op: "open", Path: "/root", Err: syscall.EACCES}, KindPermission, false},
+		{"exist", os.ErrExist, KindConflict, false},
+		{"unexpected eof", io.ErrUnexpectedEOF, KindUnavailable, true},
+		{"connection reset", &net.OpError{Op: "read", Net: "tcp", Err: os.NewSyscallError("read", syscall.ECONNRESET)}, KindUnavailable, true},
+		{"errno timeout", syscall.ETIMEDOUT, KindTimeout, true},
+		{"dns not found", &net.DNSError{Err: "no such host", Name: "missing.test", IsNotFound: true}, KindNotFound, false},
+		{"dns timeout", &net.DNSError{Err: "timeout", Name: "slow.test", IsTimeout: true}, KindTimeout, true},
+		{"dns temporary", &net.DNSError{Err: "server misbehaving", Name: "a.test", IsTemporary: true}, KindUnavailable, true},
+		{"closed", net.ErrClosed, KindUnavailable, false},
+		{"op error", &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("err")}, KindUnavailable, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requireClassified(t, tt.err, tt.kind, tt.retryable)
+		})
+	}
+
+	t.Run("nil", func(t *testing.T) {
+		if Classify(nil) != nil {
+			t.Error("not nil error")
+		}
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		err := errors.New("err")
+		if Classify(err) != err {
+			t.Error("changed error")
+		}
+	})
+
+	t.Run("existing kind", func(t *testing.T) {
+		err := WithKind(context.Canceled, KindInternal)
+		if Classify(err) != err {
+			t.Error("changed error")
+		}
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		_, err := os.Open(filepath.Join(t.TempDir(), "missing"))
+		requireClassified(t, err, KindNotFound, false)
+	})
+}
+
+func listenLoopback(t *testing.T) net.Listener {
+	t.Helper()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+
+	return l
+}
+
+// acceptOnce serves a single connection of l with serve.
+func acceptOnce(l net.Listener, serve func(net.Conn)) {
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		serve(conn)
+	}()
+}
+
+func TestClassifyLoopback(t *testing.T) {
+	t.Run("connection refused", func(t *testing.T) {
+		l := listenLoopback(t)
+		addr := l.Addr().String()
+		l.Close()
+
+		_, err := net.Dial("tcp", addr)
+		if err == nil {
+			t.Fatal("expect error")
+		}
+		requireClassified(t, err, KindUnavailable, true)
+	})
+
+	t.Run("read timeout", func(t *testing.T) {
+		l := listenLoopback(t)
+		acceptOnce(l, func(conn net.Conn) {
+			time.Sleep(time.Second)
+			conn.Close()
+		})
+
+		conn, err := net.Dial("tcp", l.Addr().String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer conn.Close()
+
+		conn.SetReadDeadline(time.Now().Add(10 * time.Millisecond))
+		_, err = conn.Read(make([]byte, 1))
+		requireClassified(t, err, KindTimeout, true)
+	})
+
+	t.Run("connection reset", func(t *testing.T) {
+		l := listenLoopback(t)
+		acceptOnce(l, func(conn net.Conn) {
+			conn.(*net.TCPConn).SetLinger(0)
+			conn.Close()
+		})
+
+		// depending on timing the reset is seen by connect or by read
+		conn, err := net.Dial("tcp", l.Addr().String())
+		if err == nil {
+			defer conn.Close()
+			_, err = conn.Read(make([]byte, 1))
+		}
+		if !errors.Is(err, syscall.ECONNRESET) {
+			t.Skipf("connection is not reset: %v", err)
+		}
+		requireClassified(t, err, KindUnavailable, true)
+	})
+
+	t.Run("unexpected eof", func(t *testing.T) {
+		l := listenLoopback(t)
+		acceptOnce(l, func(conn net.Conn) {
+			conn.Write([]byte("ab"))
+			conn.Close()
+		})
+
+		conn, err := net.Dial("tcp", l.Addr().String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer conn.Close()
+
+		_, err = io.ReadFull(conn, make([]byte, 4))
+		requireClassified(t, err, KindUnavailable, true)
+	})
+}
+
+func TestClassifyHook(t *testing.T) {
+	ctx := WithEnrichHook(context.Background(), ClassifyHook)
+
+	err := Enrich(ctx, fmt.Errorf("query: %w", context.DeadlineExceeded))
+
+	if KindOf(err) != KindTimeout || !IsRetryable(err) {
+		t.Errorf("unexpected classification: kind %v, retryable %v", KindOf(err), IsRetryable(err))
+	}
+	requireStack(t, err)
+}
@@ -0,0 +1,278 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// generateGo returns the Go file with a template and a constructor for every error,
+// and lookups of HTTP statuses, gRPC codes and visibility by error code.
+func generateGo(spec *Spec, source string) ([]byte, error) {
+	var b bytes.Buffer
+
+	fmt.Fprintf(&b, "// Code generated by cerrors-gen from %s. DO NOT EDIT.\n\n", source)
+	fmt.Fprintf(&b, "package %s\n\n", spec.Package)
+
+	imports := map[string]bool{"context": true, "github.com/sloory/cerrors": true}
+	for _, e := range spec.Errors {
+		for _, p := range e.Params {
+			if pkg := paramTypes[p.Type]; pkg != "" {
+				imports[pkg] = true
+			}
+		}
+		if e.GRPC != "" {
+			imports["google.golang.org/grpc/codes"] = true
+		}
+	}
+	writeImports(&b, imports)
+
+	for _, e := range spec.Errors {
+		fmt.Fprintf(&b, "// Err%s is the template of %s errors.\n", e.Name, e.Code)
+		if strings.TrimSpace(e.Description) != "" {
+			b.WriteString("//\n")
+		}
+		writeComment(&b, e.Description)
+		fmt.Fprintf(&b, "var Err%s = cerrors.Define(%q, cerrors.%s, %q)\n\n", e.Name, e.Code, kindConst(e.Kind), e.Message)
+	}
+
+	for _, e := range spec.Errors {
+		params := make([]string, 0, len(e.Params))
+		fields := make([]string, 0, len(e.Params))
+		for _, p := range e.Params {
+			params = append(params, p.Name+" "+p.Type)
+			fields = append(fields, fmt.Sprintf("cerrors.F(%q, %s)", p.Name, p.Name))
+		}
+
+		fmt.Fprintf(&b, "// New%s returns Err%s: %s\n", e.Name, e.Name, e.Message)
+		fmt.Fprintf(&b, "func New%s(%s) error {\n", e.Name, strings.Join(append([]string{"ctx context.Context"}, params...), ", "))
+		fmt.Fprintf(&b, "\treturn Err%s.New(%s)\n}\n\n", e.Name, strings.Join(append([]string{"ctx"}, fields...), ", "))
+	}
+
+	writeLookup(&b, spec, lookup{
+		doc:      "HTTPStatus returns the HTTP status of the error code of err, 0 for unknown codes.",
+		name:     "HTTPStatus",
+		typ:      "int",
+		fallback: "0",
+		value: func(e ErrorSpec) string {
+			if e.HTTP == 0 {
+				return ""
+			}
+			return strconv.Itoa(e.HTTP)
+		},
+	})
+	writeLookup(&b, spec, lookup{
+		doc:      "GRPCCode returns the gRPC code of the error code of err, codes.Unknown for unknown codes.",
+		name:     "GRPCCode",
+		typ:      "codes.Code",
+		fallback: "codes.Unknown",
+		value: func(e ErrorSpec) string {
+			if e.GRPC == "" {
+				return ""
+			}
+			return "codes." + e.GRPC
+		},
+	})
+	writeLookup(&b, spec, lookup{
+		doc:      "IsPublic reports whether the message of err may be shown to clients.",
+		name:     "IsPublic",
+		typ:      "bool",
+		fallback: "false",
+		value: func(e ErrorSpec) string {
+			if e.Visibility != visibilityPublic {
+				return ""
+			}
+			return "true"
+		},
+	})
+
+	src, err := format.Source(b.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("format generated code: %w", err)
+	}
+
+	return src, nil
+}
+
+func writeImports(b *bytes.Buffer, imports map[string]bool) {
+	var std, other []string
+	for pkg := range imports {
+		if strings.Contains(strings.SplitN(pkg, "/", 2)[0], ".") {
+			other = append(other, pkg)
+		} else {
+			std = append(std, pkg)
+		}
+	}
+	sort.Strings(std)
+	sort.Strings(other)
+
+	b.WriteString("import (\n")
+	for _, pkg := range std {
+		fmt.Fprintf(b, "\t%q\n", pkg)
+	}
+	b.WriteString("\n")
+	for _, pkg := range other {
+		fmt.Fprintf(b, "\t%q\n", pkg)
+	}
+	b.WriteString(")\n\n")
+}
+
+func writeComment(b *bytes.Buffer, text string) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return
+	}
+
+	for _, line := range strings.Split(text, "\n") {
+		fmt.Fprintf(b, "// %s\n", strings.TrimSpace(line))
+	}
+}
+
+type lookup struct {
+	doc, name, typ, fallback string
+	// value returns the value for the error, empty when it has none.
+	value func(ErrorSpec) string
+}
+
+// writeLookup writes a function returning values of lookup by error code,
+// grouping codes with the same value. Nothing is written when no error has a value.
+func writeLookup(b *bytes.Buffer, spec *Spec, l lookup) {
+	var values []string
+	codes := map[string][]string{}
+	for _, e := range spec.Errors {
+		v := l.value(e)
+		if v == "" {
+			continue
+		}
+		if _, ok := codes[v]; !ok {
+			values = append(values, v)
+		}
+		codes[v] = append(codes[v], strconv.Quote(e.Code))
+	}
+	if len(values) == 0 {
+		return
+	}
+
+	fmt.Fprintf(b, "// %s\n", l.doc)
+	fmt.Fprintf(b, "func %s(err error) %s {\n\tswitch cerrors.Code(err) {\n", l.name, l.typ)
+	for _, v := range values {
+		fmt.Fprintf(b, "\tcase %s:\n\t\treturn %s\n", strings.Join(codes[v], ", "), v)
+	}
+	fmt.Fprintf(b, "\t}\n\treturn %s\n}\n\n", l.fallback)
+}
+
+// generateMarkdown returns documentation of all errors.
+func generateMarkdown(spec *Spec) []byte {
+	var b bytes.Buffer
+
+	b.WriteString("# Errors\n\n")
+	b.WriteString("| Code | Kind | HTTP | gRPC | Visibility | Message |\n")
+	b.WriteString("|------|------|------|------|------------|---------|\n")
+	for _, e := range spec.Errors {
+		status := ""
+		if e.HTTP != 0 {
+			status = strconv.Itoa(e.HTTP)
+		}
+		fmt.Fprintf(&b, "| [%s](#%s) | %s | %s | %s | %s | %s |\n",
+			codeSpan(e.Code), anchor(e.Code), e.Kind, status, e.GRPC, e.Visibility, markdownCell(e.Message))
+	}
+
+	for _, e := range spec.Errors {
+		fmt.Fprintf(&b, "\n## %s\n\n", e.Code)
+		if d := strings.TrimSpace(e.Description); d != "" {
+			fmt.Fprintf(&b, "%s\n\n", d)
+		}
+		fmt.Fprintf(&b, "Message: %s\n", codeSpan(e.Message))
+
+		if len(e.Params) > 0 {
+			b.WriteString("\n| Param | Type |\n|-------|------|\n")
+			for _, p := range e.Params {
+				fmt.Fprintf(&b, "| `%s` | `%s` |\n", p.Name, p.Type)
+			}
+		}
+	}
+
+	return b.Bytes()
+}
+
+// anchor returns the GitHub anchor of the heading.
+func anchor(heading string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '-', r == '_':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r - 'A' + 'a'
+		case r == ' ':
+			return '-'
+		}
+		return -1
+	}, heading)
+}
+
+// codeSpan returns s as a markdown code span, delimited by more backticks than
+// any run of backticks in s and padded with spaces when s starts or ends with one.
+func codeSpan(s string) string {
+	longest, run := 0, 0
+	for _, r := range s {
+		if r != '`' {
+			run = 0
+			continue
+		}
+		run++
+		if run > longest {
+			longest = run
+		}
+	}
+
+	fence := strings.Repeat("`", longest+1)
+	if strings.HasPrefix(s, "`") || strings.HasSuffix(s, "`") {
+		s = " " + s + " "
+	}
+	return fence + s + fence
+}
+
+func markdownCell(s string) string {
+	return strings.ReplaceAll(s, "|", `\|`)
+}
+
+type catalogEntry struct {
+	Code    string         `json:"code"`
+	Kind    string         `json:"kind"`
+	HTTP    int            `json:"http,omitempty"`
+	GRPC    string         `json:"grpc,omitempty"`
+	Message string         `json:"message"`
+	Params  []catalogParam `json:"params,omitempty"`
+}
+
+type catalogParam struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// generateCatalog returns the JSON catalog of public errors for frontends.
+func generateCatalog(spec *Spec) ([]byte, error) {
+	catalog := []catalogEntry{}
+	for _, e := range spec.Errors {
+		if e.Visibility != visibilityPublic {
+			continue
+		}
+
+		entry := catalogEntry{Code: e.Code, Kind: e.Kind, HTTP: e.HTTP, GRPC: e.GRPC, Message: e.Message}
+		for _, p := range e.Params {
+			entry.Params = append(entry.Params, catalogParam{Name: p.Name, Type: p.Type})
+		}
+		catalog = append(catalog, entry)
+	}
+
+	data, err := json.MarshalIndent(catalog, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append(data, '\n'), nil
+}
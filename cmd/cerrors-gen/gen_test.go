@@ -0,0 +1,179 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files")
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	outputs := map[string]string{
+		"errors_gen.go": filepath.Join(dir, "errors_gen.go"),
+		"errors.md":     filepath.Join(dir, "errors.md"),
+		"errors.json":   filepath.Join(dir, "errors.json"),
+	}
+
+	in := filepath.Join("testdata", "errors.yaml")
+	if err := run(in, outputs["errors_gen.go"], outputs["errors.md"], outputs["errors.json"]); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, path := range outputs {
+		t.Run(name, func(t *testing.T) {
+			got, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			golden := filepath.Join("testdata", name+".golden")
+			if *update {
+				if err := os.WriteFile(golden, got, 0o644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+
+			expected, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if string(expected) != string(got) {
+				t.Errorf("unexpected %s, run go test -update to accept:\n%s", name, got)
+			}
+		})
+	}
+
+	t.Run("deterministic", func(t *testing.T) {
+		first, err := os.ReadFile(outputs["errors_gen.go"])
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for i := 0; i < 10; i++ {
+			if err := run(in, outputs["errors_gen.go"], "", ""); err != nil {
+				t.Fatal(err)
+			}
+			got, err := os.ReadFile(outputs["errors_gen.go"])
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(first) != string(got) {
+				t.Fatal("generated code differs between runs")
+			}
+		}
+	})
+}
+
+// grpcImporter imports google.golang.org/grpc/codes, which is not a dependency of
+// the module, from a stub declaring the constants of grpcCodes.
+type grpcImporter struct {
+	fset   *token.FileSet
+	source types.Importer
+}
+
+func (i grpcImporter) Import(path string) (*types.Package, error) {
+	if path != "google.golang.org/grpc/codes" {
+		return i.source.Import(path)
+	}
+
+	names := make([]string, 0, len(grpcCodes))
+	for name := range grpcCodes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	src := fmt.Sprintf("package codes\n\ntype Code uint32\n\nconst (\n\t%s Code = iota\n\t%s\n)\n",
+		names[0], strings.Join(names[1:], "\n\t"))
+	f, err := parser.ParseFile(i.fset, "codes.go", src, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return (&types.Config{}).Check(path, i.fset, []*ast.File{f}, nil)
+}
+
+func TestGeneratedCodeCompiles(t *testing.T) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "errors_gen.go", mustReadFile(t, filepath.Join("testdata", "errors_gen.go.golden")), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conf := types.Config{Importer: grpcImporter{fset: fset, source: importer.ForCompiler(fset, "source", nil)}}
+	if _, err := conf.Check("users", fset, []*ast.File{f}, nil); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func mustReadFile(t *testing.T, path string) []byte {
+	t.Helper()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestCodeSpan(t *testing.T) {
+	tests := []struct {
+		in, expected string
+	}{
+		{"internal error", "`internal error`"},
+		{"see `id`", "`` see `id` ``"},
+		{"a `` b", "```a `` b```"},
+		{"`id` missing", "`` `id` missing ``"},
+	}
+	for _, tt := range tests {
+		if got := codeSpan(tt.in); got != tt.expected {
+			t.Errorf("unexpected code span of %q: expected %s, got %s", tt.in, tt.expected, got)
+		}
+	}
+}
+
+func TestParseSpecErrors(t *testing.T) {
+	const valid = "  - name: A\n    code: a\n    kind: internal\n    message: a\n"
+
+	tests := []struct {
+		name, yaml, err string
+	}{
+		{"package", "package: 1a\n", `invalid package name "1a"`},
+		{"unknown key", "package: p\ncolor: red\n", "field color not found"},
+		{"name", "package: p\nerrors:\n  - name: a\n    code: a\n    kind: internal\n    message: a\n", "invalid name"},
+		{"kind", "package: p\nerrors:\n  - name: A\n    code: a\n    kind: bad\n    message: a\n", `unknown kind "bad"`},
+		{"http", "package: p\nerrors:\n" + valid + "    http: 42\n", "invalid HTTP status 42"},
+		{"grpc", "package: p\nerrors:\n" + valid + "    grpc: Nope\n", `unknown gRPC code "Nope"`},
+		{"visibility", "package: p\nerrors:\n" + valid + "    visibility: secret\n", `invalid visibility "secret"`},
+		{"param type", "package: p\nerrors:\n" + valid + "    params:\n      - name: x\n        type: any\n", `unsupported type "any"`},
+		{"param name", "package: p\nerrors:\n" + valid + "    params:\n      - name: func\n        type: int\n", `invalid param name "func"`},
+		{"reserved param name", "package: p\nerrors:\n" + valid + "    params:\n      - name: ctx\n        type: int\n", `param name "ctx" is used by the generated code`},
+		{"blank param name", "package: p\nerrors:\n" + valid + "    params:\n      - name: _\n        type: int\n", `param name "_" is used by the generated code`},
+		{"error variable param name", "package: p\nerrors:\n" + valid + "    params:\n      - name: ErrA\n        type: int\n", `param name "ErrA" is used by the generated code`},
+		{"multi-line code", "package: p\nerrors:\n  - name: A\n    code: \"a\\nb\"\n    kind: internal\n    message: a\n", "code must be a single line"},
+		{"multi-line message", "package: p\nerrors:\n  - name: A\n    code: a\n    kind: internal\n    message: \"first\\nsecond\"\n", "message must be a single line"},
+		{"placeholder", "package: p\nerrors:\n  - name: A\n    code: a\n    kind: internal\n    message: a %{id}\n", "placeholder %{id} is not a param"},
+		{"duplicate name", "package: p\nerrors:\n" + valid + strings.Replace(valid, "code: a", "code: b", 1), "duplicate error name A"},
+		{"duplicate code", "package: p\nerrors:\n" + valid + strings.Replace(valid, "name: A", "name: B", 1), "duplicate error code a"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseSpec([]byte(tt.yaml))
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("unexpected error: expected %q, got %v", tt.err, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,10 @@
+module github.com/sloory/cerrors/cmd/cerrors-gen
+
+go 1.20
+
+replace github.com/sloory/cerrors => ../../
+
+require (
+	github.com/sloory/cerrors v0.2.0
+	gopkg.in/yaml.v3 v3.0.1
+)
@@ -0,0 +1,82 @@
+// Command cerrors-gen generates error templates and typed constructors from an
+// errors.yaml catalog, with markdown documentation and a JSON catalog of public
+// errors for frontends.
+//
+//	cerrors-gen [-in errors.yaml] [-go errors_gen.go] [-md errors.md] [-json errors.json]
+//
+// Outputs with empty paths are not generated. The catalog looks like:
+//
+//	package: users
+//	errors:
+//	  - name: UserNotFound        # ErrUserNotFound and NewUserNotFound(ctx, id int64)
+//	    code: user.not_found
+//	    kind: not_found           # a cerrors.Kind name
+//	    http: 404
+//	    grpc: NotFound            # a google.golang.org/grpc/codes name
+//	    visibility: public        # public or internal, the default
+//	    message: user %{id} not found
+//	    description: The user does not exist or was deleted.
+//	    params:
+//	      - name: id            # not ctx, context, cerrors, time, codes or an ErrName
+//	        type: int64
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+func main() {
+	in := flag.String("in", "errors.yaml", "error catalog")
+	goOut := flag.String("go", "errors_gen.go", "generated Go file")
+	mdOut := flag.String("md", "", "generated markdown documentation")
+	jsonOut := flag.String("json", "", "generated JSON catalog of public errors")
+	flag.Parse()
+
+	if err := run(*in, *goOut, *mdOut, *jsonOut); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(in, goOut, mdOut, jsonOut string) error {
+	data, err := os.ReadFile(in)
+	if err != nil {
+		return err
+	}
+
+	spec, err := parseSpec(data)
+	if err != nil {
+		return fmt.Errorf("%s: %w", in, err)
+	}
+
+	if goOut != "" {
+		src, err := generateGo(spec, filepath.Base(in))
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(goOut, src, 0o644); err != nil {
+			return err
+		}
+	}
+
+	if mdOut != "" {
+		if err := os.WriteFile(mdOut, generateMarkdown(spec), 0o644); err != nil {
+			return err
+		}
+	}
+
+	if jsonOut != "" {
+		catalog, err := generateCatalog(spec)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(jsonOut, catalog, 0o644); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
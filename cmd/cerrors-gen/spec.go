@@ -0,0 +1,191 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/token"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/sloory/cerrors"
+)
+
+// Spec is the content of errors.yaml.
+type Spec struct {
+	// Package is the package name of the generated Go file.
+	Package string      `yaml:"package"`
+	Errors  []ErrorSpec `yaml:"errors"`
+}
+
+type ErrorSpec struct {
+	// Name is the Go name of the error: ErrName and NewName are generated for it.
+	Name        string `yaml:"name"`
+	Code        string `yaml:"code"`
+	Kind        string `yaml:"kind"`
+	HTTP        int    `yaml:"http"`
+	GRPC        string `yaml:"grpc"`
+	Message     string `yaml:"message"`
+	Description string `yaml:"description"`
+	// Visibility is either public, for errors shown to clients, or internal, the default.
+	Visibility string      `yaml:"visibility"`
+	Params     []ParamSpec `yaml:"params"`
+}
+
+type ParamSpec struct {
+	Name string `yaml:"name"`
+	Type string `yaml:"type"`
+}
+
+const (
+	visibilityPublic   = "public"
+	visibilityInternal = "internal"
+)
+
+// paramTypes are the Go types parameters may have, with the import they need.
+var paramTypes = map[string]string{
+	"string": "", "bool": "",
+	"int": "", "int32": "", "int64": "",
+	"uint": "", "uint32": "", "uint64": "",
+	"float32": "", "float64": "",
+	"time.Time": "time", "time.Duration": "time",
+}
+
+// grpcCodes are names of google.golang.org/grpc/codes constants.
+var grpcCodes = map[string]bool{
+	"OK": true, "Canceled": true, "Unknown": true, "InvalidArgument": true, "DeadlineExceeded": true,
+	"NotFound": true, "AlreadyExists": true, "PermissionDenied": true, "ResourceExhausted": true,
+	"FailedPrecondition": true, "Aborted": true, "OutOfRange": true, "Unimplemented": true,
+	"Internal": true, "Unavailable": true, "DataLoss": true, "Unauthenticated": true,
+}
+
+// reservedParams are names used by generated constructors, which params cannot have.
+var reservedParams = map[string]bool{
+	"_": true, "ctx": true, "context": true, "cerrors": true, "time": true, "codes": true,
+}
+
+var placeholder = regexp.MustCompile(`%\{([^}]*)\}`)
+
+func parseSpec(data []byte) (*Spec, error) {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+
+	var spec Spec
+	if err := dec.Decode(&spec); err != nil {
+		return nil, err
+	}
+
+	for i := range spec.Errors {
+		if spec.Errors[i].Visibility == "" {
+			spec.Errors[i].Visibility = visibilityInternal
+		}
+	}
+
+	return &spec, spec.validate()
+}
+
+func (s *Spec) validate() error {
+	if !token.IsIdentifier(s.Package) {
+		return fmt.Errorf("invalid package name %q", s.Package)
+	}
+
+	vars := map[string]bool{}
+	for _, e := range s.Errors {
+		vars["Err"+e.Name] = true
+	}
+
+	names, codes := map[string]bool{}, map[string]bool{}
+	for _, e := range s.Errors {
+		if err := e.validate(); err != nil {
+			return fmt.Errorf("error %s: %w", e.Name, err)
+		}
+		// constructors refer to the variables of errors, so params cannot shadow them
+		for _, p := range e.Params {
+			if vars[p.Name] {
+				return fmt.Errorf("error %s: param name %q is used by the generated code", e.Name, p.Name)
+			}
+		}
+
+		if names[e.Name] {
+			return fmt.Errorf("duplicate error name %s", e.Name)
+		}
+		names[e.Name] = true
+
+		if codes[e.Code] {
+			return fmt.Errorf("duplicate error code %s", e.Code)
+		}
+		codes[e.Code] = true
+	}
+
+	return nil
+}
+
+func (e ErrorSpec) validate() error {
+	if !token.IsIdentifier(e.Name) || !token.IsExported(e.Name) {
+		return fmt.Errorf("invalid name, expect exported Go identifier")
+	}
+	if e.Code == "" {
+		return fmt.Errorf("empty code")
+	}
+	// the code and the message go into doc comments and markdown tables
+	if strings.ContainsAny(e.Code, "\r\n") {
+		return fmt.Errorf("code must be a single line")
+	}
+	if e.Message == "" {
+		return fmt.Errorf("empty message")
+	}
+	if strings.ContainsAny(e.Message, "\r\n") {
+		return fmt.Errorf("message must be a single line")
+	}
+	if cerrors.ParseKind(e.Kind) == cerrors.KindUnknown && e.Kind != cerrors.KindUnknown.String() {
+		return fmt.Errorf("unknown kind %q", e.Kind)
+	}
+	if e.HTTP != 0 && (e.HTTP < 100 || e.HTTP > 599) {
+		return fmt.Errorf("invalid HTTP status %d", e.HTTP)
+	}
+	if e.GRPC != "" && !grpcCodes[e.GRPC] {
+		return fmt.Errorf("unknown gRPC code %q", e.GRPC)
+	}
+	if e.Visibility != visibilityPublic && e.Visibility != visibilityInternal {
+		return fmt.Errorf("invalid visibility %q, expect %s or %s", e.Visibility, visibilityPublic, visibilityInternal)
+	}
+
+	params := map[string]bool{}
+	for _, p := range e.Params {
+		if !token.IsIdentifier(p.Name) || token.IsKeyword(p.Name) {
+			return fmt.Errorf("invalid param name %q", p.Name)
+		}
+		if reservedParams[p.Name] {
+			return fmt.Errorf("param name %q is used by the generated code", p.Name)
+		}
+		if params[p.Name] {
+			return fmt.Errorf("duplicate param %s", p.Name)
+		}
+		if _, ok := paramTypes[p.Type]; !ok {
+			return fmt.Errorf("param %s: unsupported type %q", p.Name, p.Type)
+		}
+		params[p.Name] = true
+	}
+
+	for _, m := range placeholder.FindAllStringSubmatch(e.Message, -1) {
+		if !params[m[1]] {
+			return fmt.Errorf("message placeholder %s is not a param", m[0])
+		}
+	}
+
+	return nil
+}
+
+// kindConst returns the name of the cerrors constant of the kind.
+func kindConst(kind string) string {
+	var b strings.Builder
+	b.WriteString("Kind")
+	for _, part := range strings.Split(kind, "_") {
+		if part != "" {
+			b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+		}
+	}
+
+	return b.String()
+}
@@ -0,0 +1,109 @@
+// Command cerrors-migrate rewrites github.com/pkg/errors and fmt.Errorf("...: %w", err)
+// calls to github.com/sloory/cerrors.
+//
+//	cerrors-migrate [-d] [path ...]
+//
+// Without paths the current directory is migrated. Directories are walked
+// recursively, vendor, testdata and hidden directories found on the way are
+// skipped. With -d files are left untouched and a diff of the changes is printed
+// instead. pkg/errors Wrap and Wrapf become cerrors.WithStack(cerrors.Wrap(...))
+// to keep their stack. fmt.Errorf is rewritten only inside an if statement
+// checking its error is not nil, as cerrors.Wrap returns nil for a nil error,
+// other calls are reported and left as is.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func main() {
+	dryRun := flag.Bool("d", false, "print a diff instead of rewriting files")
+	flag.Parse()
+
+	paths := flag.Args()
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+
+	failed := false
+	for _, root := range paths {
+		if !migrateTree(root, *dryRun) {
+			failed = true
+		}
+	}
+
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// migrateTree migrates the Go files under root, which is skipped only by
+// walking into it, and reports whether all of them were migrated.
+func migrateTree(root string, dryRun bool) bool {
+	ok := true
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() {
+			if name := d.Name(); path != root && (name == "vendor" || name == "testdata" || strings.HasPrefix(name, ".")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if !strings.HasSuffix(path, ".go") {
+			return nil
+		}
+
+		if err := migrateFile(path, dryRun); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			ok = false
+		}
+		return nil
+	})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		ok = false
+	}
+
+	return ok
+}
+
+func migrateFile(path string, dryRun bool) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	res, err := migrate(path, src)
+	if err != nil {
+		return err
+	}
+
+	for _, note := range res.notes {
+		fmt.Fprintln(os.Stderr, note)
+	}
+
+	if !res.changed {
+		return nil
+	}
+
+	if dryRun {
+		fmt.Print(unifiedDiff(path, src, res.src))
+		return nil
+	}
+
+	return os.WriteFile(path, res.src, info.Mode().Perm())
+}
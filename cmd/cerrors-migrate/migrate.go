@@ -0,0 +1,483 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"strconv"
+	"strings"
+)
+
+const (
+	pkgErrorsPath = "github.com/pkg/errors"
+	cerrorsPath   = "github.com/sloory/cerrors"
+)
+
+// toCerrors are pkg/errors functions with a cerrors counterpart,
+// they are rewritten even if the file keeps using pkg/errors.
+var toCerrors = map[string]bool{
+	"Wrap":         true,
+	"Wrapf":        true,
+	"WithMessage":  true,
+	"WithMessagef": true,
+	"WithStack":    true,
+	"Cause":        true,
+}
+
+// toStd are pkg/errors functions which need the standard errors package, they are
+// rewritten only when pkg/errors can be dropped from the file.
+var toStd = map[string]bool{
+	"New":    true,
+	"Errorf": true,
+	"Is":     true,
+	"As":     true,
+	"Unwrap": true,
+}
+
+type result struct {
+	src     []byte
+	changed bool
+	// notes describe calls left as is
+	notes []string
+}
+
+type migration struct {
+	fset *token.FileSet
+	file *ast.File
+
+	// imports are the names of the packages imported by the file
+	imports     map[string]bool
+	pkgErrors   string
+	cerrors     string
+	dropImport  bool
+	needsStd    bool
+	needsFmt    bool
+	needsCerror bool
+	changed     bool
+	notes       []string
+	// nilChecked are the fmt.Errorf calls inside an if statement checking
+	// their error argument is not nil
+	nilChecked map[*ast.CallExpr]bool
+}
+
+func migrate(filename string, src []byte) (*result, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filename, src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &migration{
+		fset:       fset,
+		file:       file,
+		imports:    importNames(file),
+		cerrors:    importName(file, cerrorsPath, "cerrors"),
+		nilChecked: make(map[*ast.CallExpr]bool),
+	}
+	m.pkgErrors = importName(file, pkgErrorsPath, "")
+	if m.pkgErrors != "" {
+		m.dropImport = m.allUsesMigratable()
+	}
+
+	// calls are collected first so the rewritten ones are not visited again
+	var calls []*ast.CallExpr
+	var stack []ast.Node
+	ast.Inspect(file, func(n ast.Node) bool {
+		if n == nil {
+			stack = stack[:len(stack)-1]
+			return true
+		}
+
+		stack = append(stack, n)
+		if call, ok := n.(*ast.CallExpr); ok {
+			calls = append(calls, call)
+			if len(call.Args) == 2 && isNilChecked(stack, call.Args[1]) {
+				m.nilChecked[call] = true
+			}
+		}
+		return true
+	})
+
+	for _, call := range calls {
+		m.rewrite(call)
+	}
+
+	if !m.changed {
+		return &result{src: src, notes: m.notes}, nil
+	}
+
+	if m.dropImport {
+		deleteImport(file, pkgErrorsPath)
+	}
+	if m.needsStd {
+		addImport(file, "errors")
+	}
+	if m.needsFmt {
+		addImport(file, "fmt")
+	} else if !m.usesPackage("fmt") {
+		deleteImport(file, "fmt")
+	}
+	if m.needsCerror {
+		addImport(file, cerrorsPath)
+	}
+
+	var buf bytes.Buffer
+	if err := format.Node(&buf, fset, file); err != nil {
+		return nil, err
+	}
+
+	out, err := format.Source(separateImport(buf.Bytes(), cerrorsPath))
+	if err != nil {
+		return nil, err
+	}
+
+	return &result{src: out, changed: true, notes: m.notes}, nil
+}
+
+func (m *migration) allUsesMigratable() bool {
+	ok := true
+	ast.Inspect(m.file, func(n ast.Node) bool {
+		sel, isSel := n.(*ast.SelectorExpr)
+		if !isSel || !m.isPackage(sel.X, m.pkgErrors) {
+			return true
+		}
+
+		if !toCerrors[sel.Sel.Name] && !toStd[sel.Sel.Name] {
+			ok = false
+		}
+		return true
+	})
+
+	return ok
+}
+
+func (m *migration) rewrite(call *ast.CallExpr) {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return
+	}
+
+	switch {
+	case m.pkgErrors != "" && m.isPackage(sel.X, m.pkgErrors):
+		m.rewritePkgErrors(call, sel)
+	case m.isPackage(sel.X, "fmt") && sel.Sel.Name == "Errorf":
+		m.rewriteErrorf(call)
+	}
+}
+
+func (m *migration) rewritePkgErrors(call *ast.CallExpr, sel *ast.SelectorExpr) {
+	name := sel.Sel.Name
+	switch {
+	case toCerrors[name]:
+		switch name {
+		case "Wrap", "WithMessage":
+			if len(call.Args) != 2 || hasEllipsis(call) {
+				return
+			}
+			call.Args = []ast.Expr{call.Args[1], call.Args[0]}
+		case "Wrapf", "WithMessagef":
+			if len(call.Args) < 2 {
+				return
+			}
+			call.Args = []ast.Expr{m.sprintf(call.Args[1:], call.Ellipsis), call.Args[0]}
+			call.Ellipsis = token.NoPos
+		}
+
+		switch name {
+		case "Wrap", "Wrapf":
+			// unlike cerrors.Wrap, they capture the stack
+			*call = ast.CallExpr{
+				Fun:  m.cerrorsFunc("WithStack"),
+				Args: []ast.Expr{&ast.CallExpr{Fun: m.cerrorsFunc("Wrap"), Args: call.Args}},
+			}
+		case "WithMessage", "WithMessagef":
+			call.Fun = m.cerrorsFunc("Wrap")
+		default:
+			call.Fun = m.cerrorsFunc(name)
+		}
+	case toStd[name] && m.dropImport:
+		switch name {
+		case "New":
+			m.needsStd = true
+			*call = ast.CallExpr{
+				Fun:  m.cerrorsFunc("WithStack"),
+				Args: []ast.Expr{&ast.CallExpr{Fun: selector("errors", "New"), Args: call.Args}},
+			}
+		case "Errorf":
+			m.needsFmt = true
+			*call = ast.CallExpr{
+				Fun:  m.cerrorsFunc("WithStack"),
+				Args: []ast.Expr{&ast.CallExpr{Fun: selector("fmt", "Errorf"), Args: call.Args, Ellipsis: call.Ellipsis}},
+			}
+		default:
+			m.needsStd = true
+			call.Fun = selector("errors", name)
+		}
+	default:
+		m.notes = append(m.notes, fmt.Sprintf("%s: %s.%s left as is", m.fset.Position(call.Pos()), m.pkgErrors, name))
+		return
+	}
+
+	m.changed = true
+}
+
+// rewriteErrorf rewrites fmt.Errorf("msg: %w", err) without other verbs to cerrors.Wrap("msg", err)
+// when err is checked not to be nil, as cerrors.Wrap returns nil for a nil error.
+func (m *migration) rewriteErrorf(call *ast.CallExpr) {
+	if len(call.Args) != 2 || hasEllipsis(call) {
+		return
+	}
+
+	lit, ok := call.Args[0].(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return
+	}
+
+	format, err := strconv.Unquote(lit.Value)
+	if err != nil {
+		return
+	}
+
+	msg, ok := strings.CutSuffix(format, ": %w")
+	if !ok || strings.Contains(msg, "%") {
+		return
+	}
+
+	if !m.nilChecked[call] {
+		m.notes = append(m.notes, fmt.Sprintf("%s: fmt.Errorf left as is, its error may be nil", m.fset.Position(call.Pos())))
+		return
+	}
+
+	call.Fun = m.cerrorsFunc("Wrap")
+	call.Args = []ast.Expr{&ast.BasicLit{Kind: token.STRING, Value: strconv.Quote(msg)}, call.Args[1]}
+	m.changed = true
+}
+
+func (m *migration) cerrorsFunc(name string) ast.Expr {
+	m.needsCerror = true
+	return selector(m.cerrors, name)
+}
+
+func (m *migration) sprintf(args []ast.Expr, ellipsis token.Pos) ast.Expr {
+	if len(args) == 1 {
+		return args[0]
+	}
+
+	m.needsFmt = true
+	return &ast.CallExpr{Fun: selector("fmt", "Sprintf"), Args: args, Ellipsis: ellipsis}
+}
+
+func selector(pkg, name string) *ast.SelectorExpr {
+	return &ast.SelectorExpr{X: ast.NewIdent(pkg), Sel: ast.NewIdent(name)}
+}
+
+func hasEllipsis(call *ast.CallExpr) bool {
+	return call.Ellipsis.IsValid()
+}
+
+// isNilChecked reports whether expr is a variable which an if statement enclosing
+// the last node of stack, in the same function, checks not to be nil.
+func isNilChecked(stack []ast.Node, expr ast.Expr) bool {
+	ident, ok := expr.(*ast.Ident)
+	if !ok {
+		return false
+	}
+
+	for i := len(stack) - 1; i > 0; i-- {
+		switch parent := stack[i-1].(type) {
+		case *ast.FuncLit, *ast.FuncDecl:
+			return false
+		case *ast.IfStmt:
+			if stack[i] == parent.Body && isNotNil(parent.Cond, ident.Name) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// isNotNil reports whether cond is true only when the variable name is not nil.
+func isNotNil(cond ast.Expr, name string) bool {
+	bin, ok := unparen(cond).(*ast.BinaryExpr)
+	if !ok {
+		return false
+	}
+
+	switch bin.Op {
+	case token.LAND:
+		return isNotNil(bin.X, name) || isNotNil(bin.Y, name)
+	case token.NEQ:
+		return isIdent(bin.X, name) && isIdent(bin.Y, "nil") || isIdent(bin.X, "nil") && isIdent(bin.Y, name)
+	}
+
+	return false
+}
+
+// unparen is ast.Unparen, which needs Go 1.22.
+func unparen(expr ast.Expr) ast.Expr {
+	for {
+		paren, ok := expr.(*ast.ParenExpr)
+		if !ok {
+			return expr
+		}
+		expr = paren.X
+	}
+}
+
+func isIdent(expr ast.Expr, name string) bool {
+	ident, ok := unparen(expr).(*ast.Ident)
+	return ok && ident.Name == name
+}
+
+// isPackage reports whether expr refers to the package imported by the file with name.
+func (m *migration) isPackage(expr ast.Expr, name string) bool {
+	return m.imports[name] && isIdent(expr, name)
+}
+
+func (m *migration) usesPackage(name string) bool {
+	used := false
+	ast.Inspect(m.file, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok && m.isPackage(sel.X, name) {
+			used = true
+		}
+		return !used
+	})
+
+	return used
+}
+
+// importNames returns the names the packages imported by file are referred with.
+func importNames(file *ast.File) map[string]bool {
+	names := make(map[string]bool, len(file.Imports))
+	for _, imp := range file.Imports {
+		if imp.Name != nil {
+			names[imp.Name.Name] = imp.Name.Name != "_" && imp.Name.Name != "."
+			continue
+		}
+
+		path := importPath(imp)
+		names[path[strings.LastIndex(path, "/")+1:]] = true
+	}
+
+	return names
+}
+
+// importName returns the name path is imported with, def when it is imported
+// without a name and "" when it is not imported.
+func importName(file *ast.File, path, def string) string {
+	for _, imp := range file.Imports {
+		if importPath(imp) != path {
+			continue
+		}
+
+		if imp.Name != nil {
+			return imp.Name.Name
+		}
+		if def == "" {
+			return path[strings.LastIndex(path, "/")+1:]
+		}
+		return def
+	}
+
+	return def
+}
+
+func importPath(imp *ast.ImportSpec) string {
+	path, _ := strconv.Unquote(imp.Path.Value)
+	return path
+}
+
+func addImport(file *ast.File, path string) {
+	for _, imp := range file.Imports {
+		if importPath(imp) == path {
+			return
+		}
+	}
+
+	spec := &ast.ImportSpec{Path: &ast.BasicLit{Kind: token.STRING, Value: strconv.Quote(path)}}
+	file.Imports = append(file.Imports, spec)
+
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if ok && gen.Tok == token.IMPORT {
+			if !gen.Lparen.IsValid() {
+				gen.Lparen = gen.Pos()
+			}
+			gen.Specs = append(gen.Specs, spec)
+			return
+		}
+	}
+
+	file.Decls = append([]ast.Decl{&ast.GenDecl{Tok: token.IMPORT, Specs: []ast.Spec{spec}}}, file.Decls...)
+}
+
+func deleteImport(file *ast.File, path string) {
+	for i, imp := range file.Imports {
+		if importPath(imp) == path {
+			file.Imports = append(file.Imports[:i], file.Imports[i+1:]...)
+			break
+		}
+	}
+
+	for i, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.IMPORT {
+			continue
+		}
+
+		specs := gen.Specs[:0]
+		for _, spec := range gen.Specs {
+			if importPath(spec.(*ast.ImportSpec)) != path {
+				specs = append(specs, spec)
+			}
+		}
+		gen.Specs = specs
+
+		if len(gen.Specs) == 0 {
+			file.Decls = append(file.Decls[:i], file.Decls[i+1:]...)
+		}
+		return
+	}
+}
+
+// separateImport moves path into its own group when it was sorted right after
+// a standard library import.
+func separateImport(src []byte, path string) []byte {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", src, parser.ImportsOnly)
+	if err != nil {
+		return src
+	}
+
+	for i, imp := range file.Imports {
+		if i == 0 || importPath(imp) != path {
+			continue
+		}
+
+		prev := file.Imports[i-1]
+		if fset.Position(prev.End()).Line != fset.Position(imp.Pos()).Line-1 || !isStdImport(importPath(prev)) {
+			return src
+		}
+
+		lineStart := fset.Position(imp.Pos()).Offset
+		for lineStart > 0 && src[lineStart-1] != '\n' {
+			lineStart--
+		}
+
+		out := make([]byte, 0, len(src)+1)
+		out = append(out, src[:lineStart]...)
+		out = append(out, '\n')
+		return append(out, src[lineStart:]...)
+	}
+
+	return src
+}
+
+func isStdImport(path string) bool {
+	first, _, _ := strings.Cut(path, "/")
+	return !strings.Contains(first, ".")
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files")
+
+func TestMigrate(t *testing.T) {
+	tests := []struct {
+		name  string
+		notes []string
+	}{
+		{name: "pkgerrors"},
+		{name: "partial", notes: []string{
+			"testdata/partial/in.go:13:10: pkgerrors.New left as is",
+		}},
+		{name: "errorf", notes: []string{
+			"testdata/errorf/in.go:32:9: fmt.Errorf left as is, its error may be nil",
+		}},
+		{name: "unchanged"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join("testdata", tt.name, "in.go")
+			src, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			res, err := migrate(path, src)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(tt.notes, res.notes) {
+				t.Errorf("unexpected notes: expected %q, got %q", tt.notes, res.notes)
+			}
+
+			golden := filepath.Join("testdata", tt.name, "out.go.golden")
+			if *update {
+				if err := os.WriteFile(golden, res.src, 0o644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+
+			expected, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if string(expected) != string(res.src) {
+				t.Errorf("unexpected result:\n%s", unifiedDiff(golden, expected, res.src))
+			}
+
+			if res.changed != (string(src) != string(res.src)) {
+				t.Errorf("unexpected changed flag: %v", res.changed)
+			}
+		})
+	}
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	old := "a\nb\nc\nd\ne\nf\ng\nh\ni\nj\nk\nl\nm\n"
+	new := "a\nB\nc\nd\ne\nf\ng\nh\ni\nj\nk\nL\nm\n"
+
+	expected := "--- f.go\n+++ f.go\n" +
+		"@@ -1,5 +1,5 @@\n a\n-b\n+B\n c\n d\n e\n" +
+		"@@ -9,5 +9,5 @@\n i\n j\n k\n-l\n+L\n m\n"
+
+	if got := unifiedDiff("f.go", []byte(old), []byte(new)); got != expected {
+		t.Errorf("unexpected diff: expected\n%s\ngot\n%s", expected, got)
+	}
+
+	if got := unifiedDiff("f.go", []byte(old), []byte(old)); got != "" {
+		t.Errorf("unexpected diff: %q", got)
+	}
+}
+
+func TestDiffLines(t *testing.T) {
+	old := make([]string, 100000)
+	for i := range old {
+		old[i] = strconv.Itoa(i)
+	}
+	new := append([]string(nil), old...)
+	new[50000] = "changed"
+
+	edits := diffLines(old, new)
+
+	changed := 0
+	for _, e := range edits {
+		if e.op != ' ' {
+			changed++
+		}
+	}
+	if len(edits) != len(old)+1 || changed != 2 {
+		t.Errorf("unexpected edits: %d lines, %d changed", len(edits), changed)
+	}
+}
+
+func TestMigrateTree(t *testing.T) {
+	src := []byte("package a\n\nimport \"github.com/pkg/errors\"\n\nvar err = errors.WithStack(nil)\n")
+	root := t.TempDir()
+	for _, dir := range []string{"testdata", ".hidden", "vendor", "pkg"} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(root, dir, "a.go"), src, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	migrated := func(dir string) bool {
+		got, err := os.ReadFile(filepath.Join(root, dir, "a.go"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(got) != string(src)
+	}
+
+	if !migrateTree(root, false) {
+		t.Fatal("migration failed")
+	}
+	for dir, expected := range map[string]bool{"testdata": false, ".hidden": false, "vendor": false, "pkg": true} {
+		if migrated(dir) != expected {
+			t.Errorf("unexpected migration of %s: expected %v", dir, expected)
+		}
+	}
+
+	if !migrateTree(filepath.Join(root, "testdata"), false) || !migrated("testdata") {
+		t.Error("testdata given explicitly is not migrated")
+	}
+
+	if err := os.WriteFile(filepath.Join(root, "a.go"), src, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(filepath.Join(root, "pkg")); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	if !migrateTree("..", false) || !migrated("") {
+		t.Error("parent directory is not migrated")
+	}
+}
@@ -0,0 +1,33 @@
+package api
+
+import (
+	"fmt"
+	"os"
+)
+
+func open(name string) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return fmt.Errorf("open config: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := f.Stat(); err != nil {
+		return fmt.Errorf("stat %s: %w", name, err)
+	}
+
+	return nil
+}
+
+func close(f *os.File) error {
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("close: %w", err)
+	}
+
+	return nil
+}
+
+func sync(f *os.File) error {
+	err := f.Sync()
+	return fmt.Errorf("sync: %w", err)
+}
@@ -0,0 +1,17 @@
+package service
+
+import (
+	pkgerrors "github.com/pkg/errors"
+)
+
+func stack(err error) pkgerrors.StackTrace {
+	return err.(interface{ StackTrace() pkgerrors.StackTrace }).StackTrace()
+}
+
+func handle(err error) error {
+	if pkgerrors.Cause(err) == nil {
+		return pkgerrors.New("no cause")
+	}
+
+	return pkgerrors.Wrap(err, "handle")
+}
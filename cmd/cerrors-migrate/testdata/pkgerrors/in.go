@@ -0,0 +1,26 @@
+package repository
+
+import (
+	"database/sql"
+
+	"github.com/pkg/errors"
+)
+
+var ErrNotFound = errors.New("not found")
+
+func find(db *sql.DB, id int) error {
+	row := db.QueryRow("select 1 where id = $1", id)
+	if err := row.Scan(); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			// keep the sentinel
+			return errors.WithStack(ErrNotFound)
+		}
+		return errors.Wrapf(err, "find %d", id)
+	}
+
+	if id < 0 {
+		return errors.Errorf("invalid id %d", id)
+	}
+
+	return errors.Wrap(errors.WithMessage(sql.ErrConnDone, "close"), "find")
+}
@@ -0,0 +1,5 @@
+package unchanged
+
+import "errors"
+
+var ErrFailed = errors.New("failed")
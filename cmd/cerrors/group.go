@@ -0,0 +1,163 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/sloory/cerrors"
+)
+
+const topComponentsLimit = 3
+
+type componentCount struct {
+	Path  string `json:"path"`
+	Count int    `json:"count"`
+}
+
+type summary struct {
+	Place        string           `json:"place"`
+	Fingerprints []string         `json:"fingerprints"`
+	Count        int              `json:"count"`
+	Message      string           `json:"message"`
+	Code         string           `json:"code,omitempty"`
+	FirstSeen    *time.Time       `json:"first_seen,omitempty"`
+	LastSeen     *time.Time       `json:"last_seen,omitempty"`
+	Components   []componentCount `json:"components,omitempty"`
+}
+
+// group summarizes entries by their place, the most frequent errors go first.
+func group(entries []entry) []*summary {
+	byPlace := make(map[string]*summary)
+	components := make(map[string]map[string]int)
+
+	var summaries []*summary
+	for _, e := range entries {
+		d := e.details
+		place := placeFingerprint(d)
+		s, ok := byPlace[place]
+		if !ok {
+			s = &summary{Place: place, Message: d.Message, Code: d.Code}
+			byPlace[place] = s
+			components[place] = make(map[string]int)
+			summaries = append(summaries, s)
+		}
+
+		s.Count++
+		if len(d.Components) > 0 {
+			components[place][strings.Join(d.Components, "/")]++
+		}
+		if !contains(s.Fingerprints, d.Fingerprint) {
+			s.Fingerprints = append(s.Fingerprints, d.Fingerprint)
+		}
+
+		if !e.time.IsZero() {
+			t := e.time
+			if s.FirstSeen == nil || t.Before(*s.FirstSeen) {
+				s.FirstSeen = &t
+			}
+			if s.LastSeen == nil || t.After(*s.LastSeen) {
+				s.LastSeen = &t
+			}
+		}
+	}
+
+	for _, s := range summaries {
+		s.Components = topComponents(components[s.Place])
+		sort.Strings(s.Fingerprints)
+	}
+
+	sort.SliceStable(summaries, func(i, j int) bool {
+		return summaries[i].Count > summaries[j].Count
+	})
+
+	return summaries
+}
+
+// placeFingerprint is the fingerprint of d computed without its components,
+// which identifies the place the error happened in whatever components led to it.
+// It differs from the fingerprint logged with the error, which hashes components.
+func placeFingerprint(d *cerrors.Details) string {
+	place := *d
+	place.Components = nil
+
+	return place.ComputeFingerprint()
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+func topComponents(counts map[string]int) []componentCount {
+	top := make([]componentCount, 0, len(counts))
+	for path, count := range counts {
+		top = append(top, componentCount{Path: path, Count: count})
+	}
+
+	sort.Slice(top, func(i, j int) bool {
+		if top[i].Count != top[j].Count {
+			return top[i].Count > top[j].Count
+		}
+		return top[i].Path < top[j].Path
+	})
+
+	if len(top) > topComponentsLimit {
+		top = top[:topComponentsLimit]
+	}
+
+	return top
+}
+
+func writeJSON(w io.Writer, summaries []*summary) error {
+	if summaries == nil {
+		summaries = []*summary{}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(summaries)
+}
+
+func writeTable(w io.Writer, summaries []*summary) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "COUNT\tPLACE\tFIRST SEEN\tLAST SEEN\tCOMPONENTS\tMESSAGE")
+	for _, s := range summaries {
+		components := make([]string, 0, len(s.Components))
+		for _, c := range s.Components {
+			components = append(components, fmt.Sprintf("%s (%d)", c.Path, c.Count))
+		}
+
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%s\n",
+			s.Count, s.Place, formatTime(s.FirstSeen), formatTime(s.LastSeen),
+			orDash(strings.Join(components, ", ")), s.Message,
+		)
+	}
+
+	return tw.Flush()
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+
+	return t.Format(time.RFC3339)
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+
+	return s
+}
@@ -0,0 +1,111 @@
+// Command cerrors groups errors found in logs by the place they happened.
+//
+//	cerrors [--format=table|json] [--since=1h] [file ...]
+//
+// Without files logs are read from stdin. Errors are recognized in two forms:
+// JSON lines with the cerrors.MarshalJSON output, on their own or under the
+// "error" key of a log record, and errors printed with %+v, a message line
+// followed by stack frames. Lines longer than 1MB are skipped.
+//
+// The place of an error is its fingerprint computed without components, so
+// errors which happened in the same place are grouped together whatever
+// component path led to them, and the most frequent paths are listed for each
+// group. Errors printed with %+v have no components and fall into the same
+// groups as the JSON ones. Places are not the fingerprints found in logs, which
+// hash components too; the JSON output lists those of every group under
+// "fingerprints", computed like cerrors.Fingerprint for errors printed with %+v.
+//
+// --since keeps errors seen after a moment, given as a duration back from now
+// or as an RFC 3339 time. Errors without a time are dropped by it.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+func main() {
+	format := flag.String("format", "table", "output format: table or json")
+	since := flag.String("since", "", "keep errors seen after a duration back from now, like 1h, or an RFC 3339 time")
+	flag.Parse()
+
+	if err := run(os.Stdout, *format, *since, flag.Args()); err != nil {
+		fmt.Fprintln(os.Stderr, "cerrors:", err)
+		os.Exit(1)
+	}
+}
+
+func run(w io.Writer, format, since string, files []string) error {
+	if format != "table" && format != "json" {
+		return fmt.Errorf("unknown format %q", format)
+	}
+
+	sinceTime, err := parseSince(since, time.Now())
+	if err != nil {
+		return err
+	}
+
+	var entries []entry
+	if len(files) == 0 {
+		entries, err = parse(os.Stdin)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, name := range files {
+		f, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+
+		fileEntries, err := parse(f)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		entries = append(entries, fileEntries...)
+	}
+
+	groups := group(filterSince(entries, sinceTime))
+	if format == "json" {
+		return writeJSON(w, groups)
+	}
+
+	return writeTable(w, groups)
+}
+
+func parseSince(since string, now time.Time) (time.Time, error) {
+	if since == "" {
+		return time.Time{}, nil
+	}
+
+	if d, err := time.ParseDuration(since); err == nil {
+		return now.Add(-d), nil
+	}
+
+	t, err := time.Parse(time.RFC3339, since)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid --since %q: expected a duration or an RFC 3339 time", since)
+	}
+
+	return t, nil
+}
+
+func filterSince(entries []entry, since time.Time) []entry {
+	if since.IsZero() {
+		return entries
+	}
+
+	filtered := entries[:0]
+	for _, e := range entries {
+		if !e.time.IsZero() && !e.time.Before(since) {
+			filtered = append(filtered, e)
+		}
+	}
+
+	return filtered
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files")
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		since  string
+	}{
+		{name: "table", format: "table"},
+		{name: "json", format: "json"},
+		{name: "since", format: "table", since: "2023-12-10T10:02:00Z"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := run(&out, tt.format, tt.since, []string{filepath.Join("testdata", "app.log")}); err != nil {
+				t.Fatal(err)
+			}
+
+			golden := filepath.Join("testdata", tt.name+".golden")
+			if *update {
+				if err := os.WriteFile(golden, out.Bytes(), 0o644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+
+			expected, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !bytes.Equal(expected, out.Bytes()) {
+				t.Errorf("unexpected output:\n%s\nexpected:\n%s", out.String(), expected)
+			}
+		})
+	}
+
+	t.Run("unknown format", func(t *testing.T) {
+		if err := run(&bytes.Buffer{}, "yaml", "", nil); err == nil {
+			t.Error("expect error")
+		}
+	})
+}
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2023, 12, 10, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		since    string
+		expected time.Time
+	}{
+		{since: "", expected: time.Time{}},
+		{since: "1h", expected: now.Add(-time.Hour)},
+		{since: "2023-12-10T09:30:00Z", expected: time.Date(2023, 12, 10, 9, 30, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		got, err := parseSince(tt.since, now)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !got.Equal(tt.expected) {
+			t.Errorf("unexpected time for %q: expected %v, got %v", tt.since, tt.expected, got)
+		}
+	}
+
+	if _, err := parseSince("yesterday", now); err == nil {
+		t.Error("expect error")
+	}
+}
+
+func TestParseLongLine(t *testing.T) {
+	input := strings.Repeat("x", maxLineSize+1) + "\n" +
+		`{"message":"timeout","components":["worker"]}` + "\n" +
+		"connection refused\nmain.main\n\t/app/main.go:10\n"
+
+	entries, err := parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(entries) != 2 || entries[0].details.Message != "timeout" || entries[1].details.Message != "connection refused" {
+		t.Errorf("unexpected entries: %+v", entries)
+	}
+}
@@ -0,0 +1,220 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sloory/cerrors"
+)
+
+const (
+	// maxLineSize is the size of the longest line parsed, longer ones are skipped
+	maxLineSize = 1 << 20
+	// rethrownLine separates stacks of an error rethrown on another goroutine
+	rethrownLine = "rethrown at:"
+)
+
+type entry struct {
+	details *cerrors.Details
+	time    time.Time
+}
+
+var (
+	frameFileRe  = regexp.MustCompile(`^\t(.+):(\d+)$`)
+	originLineRe = regexp.MustCompile(`^(time|host|pid|goroutine|revision): (.*)$`)
+)
+
+func parse(r io.Reader) ([]entry, error) {
+	lines, err := readLines(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []entry
+	for i := 0; i < len(lines); i++ {
+		if e, ok := parseJSON(lines[i]); ok {
+			entries = append(entries, e)
+			continue
+		}
+
+		if e, n, ok := parseText(lines[i:]); ok {
+			entries = append(entries, e)
+			i += n - 1
+		}
+	}
+
+	return entries, nil
+}
+
+// readLines reads the lines of r, replacing the ones longer than maxLineSize
+// with empty lines, so they end an error printed with %+v as well.
+func readLines(r io.Reader) ([]string, error) {
+	br := bufio.NewReader(r)
+
+	var lines []string
+	var line []byte
+	tooLong := false
+	for {
+		chunk, isPrefix, err := br.ReadLine()
+		if err == io.EOF {
+			return lines, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if !tooLong {
+			line = append(line, chunk...)
+			tooLong = len(line) > maxLineSize
+		}
+		if isPrefix {
+			continue
+		}
+
+		if tooLong {
+			lines = append(lines, "")
+		} else {
+			lines = append(lines, string(line))
+		}
+		line, tooLong = line[:0], false
+	}
+}
+
+// parseJSON parses cerrors.Details, either on their own or under the "error"
+// key of a log record.
+func parseJSON(line string) (entry, bool) {
+	line = strings.TrimSpace(line)
+	if !strings.HasPrefix(line, "{") {
+		return entry{}, false
+	}
+
+	var record map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(line), &record); err != nil {
+		return entry{}, false
+	}
+
+	raw := json.RawMessage(line)
+	if errRaw, ok := record["error"]; ok && bytes.HasPrefix(bytes.TrimSpace(errRaw), []byte("{")) {
+		raw = errRaw
+	}
+
+	var d cerrors.Details
+	if err := json.Unmarshal(raw, &d); err != nil || d.Message == "" {
+		return entry{}, false
+	}
+
+	if d.Fingerprint == "" {
+		d.Fingerprint = d.ComputeFingerprint()
+	}
+
+	e := entry{details: &d, time: recordTime(record)}
+	if e.time.IsZero() && d.Origin != nil {
+		e.time = d.Origin.Time
+	}
+
+	return e, true
+}
+
+func recordTime(record map[string]json.RawMessage) time.Time {
+	for _, key := range []string{"time", "ts", "timestamp"} {
+		raw, ok := record[key]
+		if !ok {
+			continue
+		}
+
+		var s string
+		if json.Unmarshal(raw, &s) == nil {
+			if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+				return t
+			}
+		}
+
+		var seconds float64
+		if json.Unmarshal(raw, &seconds) == nil {
+			sec := int64(seconds)
+			return time.Unix(sec, int64((seconds-float64(sec))*1e9)).UTC()
+		}
+	}
+
+	return time.Time{}
+}
+
+// parseText parses an error printed with %+v: a message line followed by
+// function and file:line pairs, optionally by rethrown stacks and origin lines. It returns
+// the number of consumed lines.
+func parseText(lines []string) (entry, int, bool) {
+	if len(lines) < 3 || !isFrame(lines[1:]) {
+		return entry{}, 0, false
+	}
+
+	e := entry{}
+	message := lines[0]
+	e.time, message = cutTimePrefix(message)
+	d := &cerrors.Details{Message: message}
+
+	var n int
+	d.Stack, n = parseFrames(lines, 1)
+	for n < len(lines) && lines[n] == rethrownLine && isFrame(lines[n+1:]) {
+		var rethrown []cerrors.FrameDetails
+		rethrown, n = parseFrames(lines, n+1)
+		d.Rethrown = append(d.Rethrown, rethrown)
+	}
+
+	for ; n < len(lines); n++ {
+		m := originLineRe.FindStringSubmatch(lines[n])
+		if m == nil {
+			break
+		}
+
+		if m[1] == "time" {
+			if t, err := time.Parse(time.RFC3339Nano, m[2]); err == nil {
+				e.time = t
+			}
+		}
+	}
+
+	d.Fingerprint = d.ComputeFingerprint()
+	e.details = d
+
+	return e, n, true
+}
+
+// parseFrames parses frames starting from lines[n], it returns the index of the line after them.
+func parseFrames(lines []string, n int) ([]cerrors.FrameDetails, int) {
+	var frames []cerrors.FrameDetails
+	for ; isFrame(lines[n:]); n += 2 {
+		m := frameFileRe.FindStringSubmatch(lines[n+1])
+		line, _ := strconv.Atoi(m[2])
+		frames = append(frames, cerrors.FrameDetails{Function: lines[n], File: m[1], Line: line})
+	}
+
+	return frames, n
+}
+
+func isFrame(lines []string) bool {
+	return len(lines) >= 2 &&
+		lines[0] != "" && !strings.HasPrefix(lines[0], "\t") &&
+		frameFileRe.MatchString(lines[1])
+}
+
+// cutTimePrefix cuts a time written by a logger in front of the message,
+// either in RFC 3339 or in the standard log package format.
+func cutTimePrefix(line string) (time.Time, string) {
+	first, rest, _ := strings.Cut(line, " ")
+	if t, err := time.Parse(time.RFC3339Nano, first); err == nil {
+		return t, rest
+	}
+
+	second, rest2, _ := strings.Cut(rest, " ")
+	if t, err := time.ParseInLocation("2006/01/02 15:04:05", first+" "+second, time.Local); err == nil {
+		return t, rest2
+	}
+
+	return time.Time{}, line
+}
@@ -0,0 +1,366 @@
+// Package analyzer reports:
+//   - errors returned without cerrors.Enrich from functions which called cerrors.InComponent,
+//   - cerrors.Opaque called with an empty message,
+//   - cerrors.WithField and cerrors.WithFieldContext called with a non-constant key,
+//   - fields with sensitive names, like password or token.
+package analyzer
+
+import (
+	"bytes"
+	"go/ast"
+	"go/constant"
+	"go/format"
+	"go/token"
+	"go/types"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+const cerrorsPath = "github.com/sloory/cerrors"
+
+var Analyzer = &analysis.Analyzer{
+	Name:     "cerrorslint",
+	Doc:      "reports misuse of github.com/sloory/cerrors",
+	Run:      run,
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+}
+
+var sensitive = "password,passwd,secret,token,apikey,authorization,cookie,creditcard,cvv"
+
+func init() {
+	Analyzer.Flags.StringVar(&sensitive, "sensitive", sensitive,
+		"comma separated substrings of field names which must not be attached to errors")
+}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	insp := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+
+	nodes := []ast.Node{(*ast.FuncDecl)(nil), (*ast.FuncLit)(nil), (*ast.CallExpr)(nil)}
+	insp.Preorder(nodes, func(n ast.Node) {
+		switch n := n.(type) {
+		case *ast.FuncDecl:
+			if n.Body != nil {
+				checkComponentReturns(pass, n.Type, n.Body)
+			}
+		case *ast.FuncLit:
+			checkComponentReturns(pass, n.Type, n.Body)
+		case *ast.CallExpr:
+			checkCall(pass, n)
+		}
+	})
+
+	return nil, nil
+}
+
+// cerrorsFunc returns the name of the cerrors function called by call.
+func cerrorsFunc(pass *analysis.Pass, call *ast.CallExpr) string {
+	fn, ok := typeutil.Callee(pass.TypesInfo, call).(*types.Func)
+	if !ok || fn.Pkg() == nil || fn.Pkg().Path() != cerrorsPath {
+		return ""
+	}
+
+	return fn.Name()
+}
+
+func checkCall(pass *analysis.Pass, call *ast.CallExpr) {
+	switch cerrorsFunc(pass, call) {
+	case "Opaque":
+		if len(call.Args) == 2 && isConstString(pass, call.Args[0], "") {
+			pass.Reportf(call.Pos(), "cerrors.Opaque called with an empty message")
+		}
+	case "WithField":
+		if len(call.Args) != 3 {
+			return
+		}
+		checkFieldKey(pass, call, "WithField", call.Args[0], call.Args[1])
+	case "WithFieldContext":
+		if len(call.Args) != 4 {
+			return
+		}
+		checkFieldKey(pass, call, "WithFieldContext", call.Args[1], call.Args[2])
+	case "WithCtxField":
+		if len(call.Args) != 3 {
+			return
+		}
+		if key, ok := constString(pass, call.Args[1]); ok {
+			checkSensitive(pass, call, key, call.Args[0])
+		}
+	case "WithFields":
+		if len(call.Args) != 2 {
+			return
+		}
+		checkFieldsLit(pass, call.Args[1])
+	case "WithFieldsContext":
+		if len(call.Args) != 3 {
+			return
+		}
+		checkFieldsLit(pass, call.Args[2])
+	}
+}
+
+// checkFieldKey reports a non-constant or sensitive key of the call to fn,
+// which attaches a field to err.
+func checkFieldKey(pass *analysis.Pass, call *ast.CallExpr, fn string, err, key ast.Expr) {
+	name, ok := constString(pass, key)
+	if !ok {
+		pass.Reportf(key.Pos(), "cerrors.%s called with a non-constant key", fn)
+		return
+	}
+	checkSensitive(pass, call, name, err)
+}
+
+// checkFieldsLit reports sensitive keys of fields given as a map literal.
+func checkFieldsLit(pass *analysis.Pass, fields ast.Expr) {
+	lit, ok := ast.Unparen(fields).(*ast.CompositeLit)
+	if !ok {
+		return
+	}
+	for _, elt := range lit.Elts {
+		kv, ok := elt.(*ast.KeyValueExpr)
+		if !ok {
+			continue
+		}
+		if key, ok := constString(pass, kv.Key); ok && isSensitive(key) {
+			pass.Reportf(kv.Pos(), "field %q may contain sensitive data", key)
+		}
+	}
+}
+
+// checkSensitive reports a sensitive key and suggests to drop the call,
+// keeping its first argument.
+func checkSensitive(pass *analysis.Pass, call *ast.CallExpr, key string, keep ast.Expr) {
+	if !isSensitive(key) {
+		return
+	}
+
+	pass.Report(analysis.Diagnostic{
+		Pos:     call.Pos(),
+		End:     call.End(),
+		Message: "field " + strconv.Quote(key) + " may contain sensitive data",
+		SuggestedFixes: []analysis.SuggestedFix{{
+			Message: "Remove field " + strconv.Quote(key),
+			TextEdits: []analysis.TextEdit{{
+				Pos:     call.Pos(),
+				End:     call.End(),
+				NewText: []byte(render(pass.Fset, keep)),
+			}},
+		}},
+	})
+}
+
+func isSensitive(key string) bool {
+	normalized := strings.NewReplacer("_", "", "-", "", ".", "").Replace(strings.ToLower(key))
+	for _, s := range strings.Split(sensitive, ",") {
+		if s = strings.TrimSpace(s); s != "" && strings.Contains(normalized, s) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// checkComponentReturns reports returned errors which do not pass through cerrors.Enrich
+// in a function which received a context, once it called cerrors.InComponent.
+func checkComponentReturns(pass *analysis.Pass, typ *ast.FuncType, body *ast.BlockStmt) {
+	if !hasCtxParam(pass, typ) {
+		return
+	}
+
+	assigns := assignments(pass, body)
+	errorType := types.Universe.Lookup("error").Type()
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FuncLit:
+			// checked on its own
+			return false
+		case *ast.ReturnStmt:
+			ctxName := componentCtx(pass, assigns, n.Pos())
+			if ctxName == "" {
+				return true
+			}
+
+			for _, result := range n.Results {
+				tv, ok := pass.TypesInfo.Types[result]
+				if !ok || tv.IsNil() || !types.Identical(tv.Type, errorType) {
+					continue
+				}
+				if isEnriched(pass, assigns, result, n.Pos()) {
+					continue
+				}
+
+				diag := analysis.Diagnostic{
+					Pos:     result.Pos(),
+					End:     result.End(),
+					Message: "error is returned from a component without cerrors.Enrich",
+				}
+				// a dot-import leaves no name to qualify Enrich with
+				if pkgName := cerrorsName(pass, result); pkgName != "." {
+					diag.SuggestedFixes = []analysis.SuggestedFix{{
+						Message: "Wrap with cerrors.Enrich",
+						TextEdits: []analysis.TextEdit{{
+							Pos:     result.Pos(),
+							End:     result.End(),
+							NewText: []byte(pkgName + ".Enrich(" + ctxName + ", " + render(pass.Fset, result) + ")"),
+						}},
+					}}
+				}
+				pass.Report(diag)
+			}
+		}
+		return true
+	})
+}
+
+func hasCtxParam(pass *analysis.Pass, typ *ast.FuncType) bool {
+	for _, field := range typ.Params.List {
+		named, ok := pass.TypesInfo.TypeOf(field.Type).(*types.Named)
+		if ok && named.Obj().Pkg() != nil && named.Obj().Pkg().Path() == "context" && named.Obj().Name() == "Context" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// assignment is a value assigned to a variable.
+type assignment struct {
+	obj   types.Object
+	pos   token.Pos
+	value ast.Expr
+}
+
+// assignments returns the assignments to variables in body in source order,
+// not looking into nested functions.
+func assignments(pass *analysis.Pass, body *ast.BlockStmt) []assignment {
+	var assigns []assignment
+	add := func(ident *ast.Ident, pos token.Pos, value ast.Expr) {
+		if obj := pass.TypesInfo.ObjectOf(ident); obj != nil && ident.Name != "_" {
+			assigns = append(assigns, assignment{obj: obj, pos: pos, value: value})
+		}
+	}
+
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.AssignStmt:
+			if len(n.Lhs) != len(n.Rhs) {
+				return true
+			}
+			for i, lhs := range n.Lhs {
+				if ident, ok := lhs.(*ast.Ident); ok {
+					add(ident, n.Pos(), n.Rhs[i])
+				}
+			}
+		case *ast.ValueSpec:
+			if len(n.Names) != len(n.Values) {
+				return true
+			}
+			for i, name := range n.Names {
+				add(name, n.Pos(), n.Values[i])
+			}
+		}
+		return true
+	})
+
+	return assigns
+}
+
+// componentCtx returns the name of the variable assigned the result of
+// cerrors.InComponent last before pos, which is still visible at pos.
+func componentCtx(pass *analysis.Pass, assigns []assignment, pos token.Pos) string {
+	scope := pass.Pkg.Scope().Innermost(pos)
+	if scope == nil {
+		return ""
+	}
+
+	for i := len(assigns) - 1; i >= 0; i-- {
+		a := assigns[i]
+		if a.pos >= pos {
+			continue
+		}
+
+		call, ok := ast.Unparen(a.value).(*ast.CallExpr)
+		if !ok || cerrorsFunc(pass, call) != "InComponent" {
+			continue
+		}
+
+		if _, obj := scope.LookupParent(a.obj.Name(), pos); obj == a.obj {
+			return a.obj.Name()
+		}
+	}
+
+	return ""
+}
+
+// isEnriched reports whether expr, evaluated at pos, is the result of cerrors.Enrich,
+// possibly wrapped after it, or a variable last assigned such a value before pos.
+func isEnriched(pass *analysis.Pass, assigns []assignment, expr ast.Expr, pos token.Pos) bool {
+	switch e := ast.Unparen(expr).(type) {
+	case *ast.CallExpr:
+		if cerrorsFunc(pass, e) == "Enrich" {
+			return true
+		}
+		for _, arg := range e.Args {
+			if isEnriched(pass, assigns, arg, pos) {
+				return true
+			}
+		}
+	case *ast.Ident:
+		obj := pass.TypesInfo.ObjectOf(e)
+		for i := len(assigns) - 1; i >= 0; i-- {
+			if a := assigns[i]; a.pos < pos && a.obj == obj {
+				return isEnriched(pass, assigns, a.value, a.pos)
+			}
+		}
+	}
+
+	return false
+}
+
+// cerrorsName returns the name the cerrors package is imported with in the file of node.
+func cerrorsName(pass *analysis.Pass, node ast.Node) string {
+	for _, file := range pass.Files {
+		if file.Pos() > node.Pos() || node.Pos() >= file.End() {
+			continue
+		}
+		for _, imp := range file.Imports {
+			if strings.Trim(imp.Path.Value, `"`) != cerrorsPath {
+				continue
+			}
+			if imp.Name != nil {
+				return imp.Name.Name
+			}
+		}
+	}
+
+	return "cerrors"
+}
+
+func constString(pass *analysis.Pass, expr ast.Expr) (string, bool) {
+	tv, ok := pass.TypesInfo.Types[expr]
+	if !ok || tv.Value == nil || tv.Value.Kind() != constant.String {
+		return "", false
+	}
+
+	return constant.StringVal(tv.Value), true
+}
+
+func isConstString(pass *analysis.Pass, expr ast.Expr, value string) bool {
+	s, ok := constString(pass, expr)
+	return ok && s == value
+}
+
+func render(fset *token.FileSet, node ast.Node) string {
+	var buf bytes.Buffer
+	if err := format.Node(&buf, fset, node); err != nil {
+		return ""
+	}
+
+	return buf.String()
+}
@@ -0,0 +1,103 @@
+package a
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/sloory/cerrors"
+)
+
+var errNotFound = errors.New("not found")
+
+func load() error { return errNotFound }
+
+func service(ctx context.Context) error {
+	ctx = cerrors.InComponent(ctx, "service")
+
+	if err := load(); err != nil {
+		return err // want "error is returned from a component without cerrors.Enrich"
+	}
+
+	if err := load(); err != nil {
+		return cerrors.Enrich(ctx, err)
+	}
+
+	return nil
+}
+
+func withResult(ctx context.Context) (int, error) {
+	serviceCtx := cerrors.InComponent(ctx, "service")
+
+	_ = serviceCtx
+
+	go func() error {
+		return load()
+	}()
+
+	return 0, errors.New("failed") // want "error is returned from a component without cerrors.Enrich"
+}
+
+func returnBeforeComponent(ctx context.Context) error {
+	if err := load(); err != nil {
+		return err
+	}
+
+	serviceCtx := cerrors.InComponent(ctx, "service")
+	_ = serviceCtx
+
+	return load() // want "error is returned from a component without cerrors.Enrich"
+}
+
+func enrichedBefore(ctx context.Context) error {
+	ctx = cerrors.InComponent(ctx, "service")
+
+	err := load()
+	if err != nil {
+		err = cerrors.Enrich(ctx, err)
+		return err
+	}
+
+	if err := cerrors.Enrich(ctx, load()); err != nil {
+		return fmt.Errorf("load: %w", err)
+	}
+
+	err = fmt.Errorf("load: %w", cerrors.Enrich(ctx, load()))
+	return err
+}
+
+func withoutComponent(ctx context.Context) error {
+	return load()
+}
+
+func withoutCtx() error {
+	cerrors.InComponent(context.Background(), "service")
+
+	return load()
+}
+
+func opaque(err error) error {
+	if err != nil {
+		return cerrors.Opaque("", err) // want "cerrors.Opaque called with an empty message"
+	}
+
+	return cerrors.Opaque("internal error", err)
+}
+
+const userKey = "userId"
+
+func fields(ctx context.Context, key string, err error) {
+	_ = cerrors.WithField(err, key, 1) // want "cerrors.WithField called with a non-constant key"
+	_ = cerrors.WithField(err, userKey, 1)
+	_ = cerrors.WithField(cerrors.WithField(err, "user_password", "qwerty"), "db", "users") // want `field "user_password" may contain sensitive data`
+	_ = cerrors.WithCtxField(ctx, "Auth-Token", "abc")                                      // want `field "Auth-Token" may contain sensitive data`
+	_ = cerrors.WithFields(err, map[string]any{
+		"apiKey": "abc", // want `field "apiKey" may contain sensitive data`
+		"db":     "users",
+	})
+	_ = cerrors.WithFieldContext(ctx, err, key, 1)                 // want "cerrors.WithFieldContext called with a non-constant key"
+	_ = cerrors.WithFieldContext(ctx, err, "session_token", "abc") // want `field "session_token" may contain sensitive data`
+	_ = cerrors.WithFieldsContext(ctx, err, map[string]any{
+		"password": "qwerty", // want `field "password" may contain sensitive data`
+	})
+}
@@ -0,0 +1,14 @@
+package dot
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/sloory/cerrors"
+)
+
+func service(ctx context.Context) error {
+	ctx = InComponent(ctx, "service")
+
+	return errors.New("failed") // want "error is returned from a component without cerrors.Enrich"
+}
@@ -0,0 +1,13 @@
+// Package cerrors is a stub of github.com/sloory/cerrors for analyzer tests.
+package cerrors
+
+import "context"
+
+func Enrich(ctx context.Context, err error) error                                   { return err }
+func InComponent(ctx context.Context, component string) context.Context             { return ctx }
+func Opaque(msg string, err error) error                                            { return err }
+func WithField(err error, key string, value any) error                              { return err }
+func WithFields(err error, fields map[string]any) error                             { return err }
+func WithCtxField(ctx context.Context, k string, v any) context.Context             { return ctx }
+func WithFieldContext(ctx context.Context, err error, key string, value any) error  { return err }
+func WithFieldsContext(ctx context.Context, err error, fields map[string]any) error { return err }
@@ -0,0 +1,14 @@
+// Command cerrorslint reports misuse of github.com/sloory/cerrors.
+//
+//	go run github.com/sloory/cerrors/cmd/cerrorslint ./...
+package main
+
+import (
+	"golang.org/x/tools/go/analysis/singlechecker"
+
+	"github.com/sloory/cerrors/cmd/cerrorslint/analyzer"
+)
+
+func main() {
+	singlechecker.Main(analyzer.Analyzer)
+}
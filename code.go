@@ -0,0 +1,54 @@
+package cerrors
+
+import (
+	"errors"
+	"fmt"
+)
+
+type withCode interface {
+	error
+	fmt.Formatter
+
+	Code() string
+}
+
+// check interface implementation
+var _ withCode = (*withCodeError)(nil)
+
+type withCodeError struct {
+	cause error
+	code  string
+}
+
+func newWithCode(err error, code string) error {
+	if err == nil {
+		return nil
+	}
+
+	return &withCodeError{cause: err, code: code}
+}
+
+func (w *withCodeError) Error() string { return w.cause.Error() }
+func (w *withCodeError) Unwrap() error { return w.cause }
+func (w *withCodeError) Code() string  { return w.code }
+func (w *withCodeError) Format(f fmt.State, verb rune) {
+	fmt.Fprintf(f, fmt.FormatString(f, verb), w.cause)
+}
+
+func WithCode(err error, code string) error {
+	return newWithCode(err, code)
+}
+
+// Code returns the outermost code in the chain of err.
+func Code(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	var cErr withCode
+	if errors.As(err, &cErr) {
+		return cErr.Code()
+	}
+
+	return ""
+}
@@ -27,7 +27,7 @@ type withComponentsError struct {
 	components []string
 }
 
-func enrichWithComponents(ctx context.Context, err error) error {
+func enrichWithComponents(err error, comps []string) error {
 	if err == nil {
 		return nil
 	}
@@ -37,7 +37,6 @@ func enrichWithComponents(ctx context.Context, err error) error {
 		return err
 	}
 
-	comps := getCtxComponents(ctx)
 	if len(comps) == 0 {
 		return err
 	}
@@ -49,7 +48,7 @@ func (w *withComponentsError) Error() string        { return w.cause.Error() }
 func (w *withComponentsError) Unwrap() error        { return w.cause }
 func (w *withComponentsError) Components() []string { return w.components }
 func (w *withComponentsError) Format(f fmt.State, verb rune) {
-	fmt.Printf(fmt.FormatString(f, verb), w.cause)
+	fmt.Fprintf(f, fmt.FormatString(f, verb), w.cause)
 }
 
 func getCtxComponents(ctx context.Context) []string {
@@ -63,7 +62,9 @@ func getCtxComponents(ctx context.Context) []string {
 
 func InComponent(ctx context.Context, component string) context.Context {
 	components := getCtxComponents(ctx)
-	components = append(components, component)
+	// a full slice expression makes append copy, so contexts derived from the same
+	// parent, like those of Group tasks, never share the backing array
+	components = append(components[:len(components):len(components)], component)
 
 	return context.WithValue(ctx, componentsKey, components)
 }
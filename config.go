@@ -0,0 +1,143 @@
+package cerrors
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	configKey key = 4
+)
+
+const defaultStackDepth = 32
+
+type MergePolicy int
+
+const (
+	// MergeOverwrite replaces the value of an already attached field.
+	MergeOverwrite MergePolicy = iota
+	// MergeKeepExisting keeps the value which was attached first.
+	MergeKeepExisting
+)
+
+// StackFormat is the layout of stack frames printed with %+v.
+type StackFormat int
+
+const (
+	// StackFormatMultiline prints the function and the file:line of every frame
+	// on two lines, as github.com/pkg/errors does.
+	StackFormatMultiline StackFormat = iota
+	// StackFormatCompact prints every frame on a single line as function file:line.
+	StackFormatCompact
+)
+
+type Config struct {
+	// StackDepth limits the number of captured frames, 32 by default.
+	StackDepth int
+	// StackFilter drops every captured frame it returns false for.
+	StackFilter func(Frame) bool
+	// HasStack reports whether err carries a stack of a library cerrors does not know,
+	// so no second stack is captured for it. It is called for every error of the chain.
+	//
+	// Stacks of errors with a StackTrace method returning a slice of uintptr based
+	// frames, like those of github.com/pkg/errors, and of errors with a Callers() []uintptr
+	// method, like those of github.com/go-errors/errors, are recognized without it.
+	//
+	//	HasStack: func(err error) bool {
+	//		_, ok := err.(interface{ Stack() []byte })
+	//		return ok
+	//	},
+	HasStack func(err error) bool
+	// StackFormat is the layout of frames printed with %+v, StackFormatMultiline by default.
+	StackFormat StackFormat
+	// SkipStack disables stack capturing in Enrich. WithStack always captures a stack.
+	SkipStack bool
+	// RethrowStacks makes Enrich and WithStack capture one more stack for an error which
+	// already has one, when the error crossed goroutines or is enriched from a different
+	// stack root. %+v and Stacks show all of them.
+	RethrowStacks bool
+	// Redactor is applied to every field value before it is attached to an error.
+	Redactor func(key string, value any) any
+	// FieldMerge decides which value wins when the same field is attached twice.
+	FieldMerge MergePolicy
+	// Clock is used for breadcrumbs and origin timestamps, time.Now by default.
+	Clock func() time.Time
+	// Hooks run in order inside Enrich before hooks added with WithEnrichHook.
+	Hooks []EnrichHook
+	// CaptureOrigin makes Enrich record when and where an error happened, see OriginOf.
+	CaptureOrigin bool
+	// SourceContext makes %+v print this many lines of source before and after
+	// every frame of the application. It is meant for local development only,
+	// source files are read on first use and the last 64 of them are cached.
+	SourceContext int
+	// MaxFields caps the number of fields, and of elements of every map and slice in
+	// field values, in Describe and encoders built on it. 64 by default.
+	MaxFields int
+	// MaxFieldBytes caps the size of strings in field values, 1024 bytes by default.
+	MaxFieldBytes int
+	// MaxFieldDepth caps the nesting of maps, slices and structs in field values, 5 by default.
+	MaxFieldDepth int
+}
+
+var globalConfig atomic.Pointer[Config]
+
+func init() {
+	globalConfig.Store(&Config{})
+}
+
+// Configure replaces the global configuration. It is safe to call concurrently with
+// any other function of the package.
+func Configure(cfg Config) {
+	globalConfig.Store(&cfg)
+}
+
+// WithConfig overrides the global configuration for everything enriched with ctx.
+func WithConfig(ctx context.Context, cfg Config) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	return context.WithValue(ctx, configKey, &cfg)
+}
+
+func currentConfig() *Config {
+	return globalConfig.Load()
+}
+
+func configFrom(ctx context.Context) *Config {
+	if ctx == nil {
+		return currentConfig()
+	}
+
+	cfg, ok := ctx.Value(configKey).(*Config)
+	if !ok {
+		return currentConfig()
+	}
+
+	return cfg
+}
+
+func (c *Config) now() time.Time {
+	if c.Clock == nil {
+		return time.Now()
+	}
+
+	return c.Clock()
+}
+
+func (c *Config) stackDepth() int {
+	if c.StackDepth <= 0 {
+		return defaultStackDepth
+	}
+
+	return c.StackDepth
+}
+
+func (c *Config) redact(key string, value any) any {
+	if c.Redactor == nil {
+		return value
+	}
+
+	return c.Redactor(key, value)
+}
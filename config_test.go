@@ -0,0 +1,173 @@
+package cerrors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestConfigure(t *testing.T) {
+	t.Run("stack depth", func(t *testing.T) {
+		configureForTest(t, Config{StackDepth: 2})
+
+		err := WithStack(errors.New("err"))
+
+		var stErr stackTrace
+		if !errors.As(err, &stErr) {
+			t.Fatal("expect error with stacktrace")
+		}
+
+		if len(stErr.StackTrace()) != 2 {
+			t.Errorf("unexpected stack length: expected %d, got %d", 2, len(stErr.StackTrace()))
+		}
+	})
+
+	t.Run("stack filter", func(t *testing.T) {
+		configureForTest(t, Config{StackFilter: func(f Frame) bool {
+			return !strings.HasPrefix(f.name(), "runtime.")
+		}})
+
+		err := WithStack(errors.New("err"))
+
+		var stErr stackTrace
+		if !errors.As(err, &stErr) {
+			t.Fatal("expect error with stacktrace")
+		}
+
+		for _, f := range stErr.StackTrace() {
+			if strings.HasPrefix(f.name(), "runtime.") {
+				t.Errorf("unexpected frame: %s", f.name())
+			}
+		}
+	})
+
+	t.Run("skip stack", func(t *testing.T) {
+		configureForTest(t, Config{SkipStack: true})
+
+		var stErr stackTrace
+		if errors.As(Enrich(context.Background(), errors.New("err")), &stErr) {
+			t.Error("expect error without stacktrace")
+		}
+
+		requireStack(t, WithStack(errors.New("err")))
+	})
+
+	t.Run("redactor", func(t *testing.T) {
+		configureForTest(t, Config{Redactor: func(key string, value any) any {
+			if key == "password" {
+				return "[REDACTED]"
+			}
+			return value
+		}})
+
+		err := WithFields(errors.New("err"), map[string]any{"password": "secret", "login": "admin"})
+
+		requireFields(t, err, map[string]any{"password": "[REDACTED]", "login": "admin"})
+	})
+
+	t.Run("keep existing fields", func(t *testing.T) {
+		configureForTest(t, Config{FieldMerge: MergeKeepExisting})
+
+		err := WithField(errors.New("err"), "key", "first")
+		err = WithField(err, "key", "second")
+
+		requireFields(t, err, map[string]any{"key": "first"})
+	})
+
+	t.Run("compact stack format", func(t *testing.T) {
+		configureForTest(t, Config{StackFormat: StackFormatCompact, StackDepth: 1})
+
+		err := WithStack(errors.New("err"))
+
+		formatted := fmt.Sprintf("%+v", err)
+		expected := regexp.MustCompile(`^err\n\S+\.TestConfigure\.func\d+ \S+/config_test\.go:\d+$`)
+		if !expected.MatchString(formatted) {
+			t.Errorf("unexpected format: %q", formatted)
+		}
+	})
+
+	t.Run("concurrent", func(t *testing.T) {
+		configureForTest(t, Config{})
+
+		wg := sync.WaitGroup{}
+		for i := 0; i < 10; i++ {
+			wg.Add(2)
+			go func(i int) {
+				defer wg.Done()
+				Configure(Config{StackDepth: i + 1})
+			}(i)
+			go func() {
+				defer wg.Done()
+				requireStack(t, Enrich(context.Background(), errors.New("err")))
+			}()
+		}
+		wg.Wait()
+	})
+}
+
+func TestWithConfig(t *testing.T) {
+	t.Run("overrides global", func(t *testing.T) {
+		configureForTest(t, Config{SkipStack: true})
+
+		ctx := WithConfig(context.Background(), Config{})
+
+		requireStack(t, Enrich(ctx, errors.New("err")))
+	})
+
+	t.Run("ctx fields redactor", func(t *testing.T) {
+		ctx := WithConfig(context.Background(), Config{Redactor: func(key string, value any) any {
+			return "***"
+		}})
+		ctx = WithCtxField(ctx, "token", "secret")
+
+		err := Enrich(ctx, errors.New("err"))
+
+		requireFields(t, err, map[string]any{"token": "***"})
+	})
+
+	t.Run("fields redactor", func(t *testing.T) {
+		ctx := WithConfig(context.Background(), Config{Redactor: func(key string, value any) any {
+			return "***"
+		}})
+
+		err := WithFieldContext(ctx, errors.New("err"), "token", "secret")
+		err = WithFieldsContext(ctx, err, map[string]any{"password": "secret"})
+
+		requireFields(t, err, map[string]any{"token": "***", "password": "***"})
+	})
+
+	t.Run("stack format", func(t *testing.T) {
+		ctx := WithConfig(context.Background(), Config{StackFormat: StackFormatCompact})
+
+		err := Enrich(ctx, errors.New("err"))
+
+		if strings.Contains(fmt.Sprintf("%+v", err), "\n\t") {
+			t.Errorf("unexpected multiline frames: %q", fmt.Sprintf("%+v", err))
+		}
+	})
+
+	t.Run("clock", func(t *testing.T) {
+		now := time.Date(2023, 12, 10, 0, 0, 0, 0, time.UTC)
+		ctx := WithConfig(context.Background(), Config{
+			CaptureOrigin: true,
+			Clock:         func() time.Time { return now },
+		})
+		ctx = WithBreadcrumbs(ctx, 10)
+		Breadcrumb(ctx, "db", "db query", nil)
+
+		err := Enrich(ctx, errors.New("err"))
+
+		if !Time(err).Equal(now) {
+			t.Errorf("unexpected time: expected %v, got %v", now, Time(err))
+		}
+
+		if !Breadcrumbs(err)[0].Time.Equal(now) {
+			t.Errorf("unexpected breadcrumb time: expected %v, got %v", now, Breadcrumbs(err)[0].Time)
+		}
+	})
+}
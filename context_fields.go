@@ -35,24 +35,33 @@ func CtxFields(ctx context.Context) map[string]any {
 	return fields
 }
 
-func enrichWithFields(ctx context.Context, err error) error {
-	if err == nil {
-		return nil
+// withoutCtxFields returns ctx without the fields with keys of fields.
+func withoutCtxFields(ctx context.Context, fields map[string]any) context.Context {
+	ctxFields := CtxFields(ctx)
+
+	rest := make(map[string]any, len(ctxFields))
+	for k, v := range ctxFields {
+		if _, ok := fields[k]; !ok {
+			rest[k] = v
+		}
+	}
+	if len(rest) == len(ctxFields) {
+		return ctx
 	}
 
-	fields := CtxFields(ctx)
-	if fields == nil {
-		return err
+	return context.WithValue(ctx, fieldsKey, rest)
+}
+
+func enrichWithFields(cfg *Config, err error, fields map[string]any) error {
+	if err == nil {
+		return nil
 	}
 
-	fErr := newWithFields(err)
 	if len(fields) == 0 {
 		return err
 	}
 
-	for key, value := range fields {
-		fErr.AddField(key, value)
-	}
-
-	return fErr
+	fErr, err := newWithFields(err)
+	mergeFields(cfg, fErr, fields)
+	return err
 }
@@ -0,0 +1,115 @@
+package cerrors
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// Details is everything attached to an error in a form suitable for serialization.
+type Details struct {
+	Message     string         `json:"message"`
+	Chain       []string       `json:"chain,omitempty"`
+	Code        string         `json:"code,omitempty"`
+	Kind        string         `json:"kind,omitempty"`
+	Retryable   bool           `json:"retryable,omitempty"`
+	Components  []string       `json:"components,omitempty"`
+	Fields      map[string]any `json:"fields,omitempty"`
+	Breadcrumbs []Crumb        `json:"breadcrumbs,omitempty"`
+	Origin      *Origin        `json:"origin,omitempty"`
+	Fingerprint string         `json:"fingerprint"`
+	Stack       []FrameDetails `json:"stack,omitempty"`
+	// Rethrown are stacks where the error was rethrown, from the earliest one.
+	Rethrown [][]FrameDetails `json:"rethrown,omitempty"`
+}
+
+type FrameDetails struct {
+	Function string `json:"function"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+}
+
+// Describe collects everything attached to err. Field values are normalized to strings,
+// numbers, booleans, nil, []any and map[string]any within the limits of Config, see
+// Config.MaxFields. TruncatedField is set when any of them was applied.
+func Describe(err error) *Details {
+	if err == nil {
+		return nil
+	}
+
+	d := &Details{
+		Message:     err.Error(),
+		Chain:       chainMessages(err),
+		Code:        Code(err),
+		Retryable:   IsRetryable(err),
+		Components:  Components(err),
+		Fields:      normalizeFields(currentConfig(), Fields(err)),
+		Breadcrumbs: Breadcrumbs(err),
+		Fingerprint: Fingerprint(err),
+	}
+
+	if kind := KindOf(err); kind != KindUnknown {
+		d.Kind = kind.String()
+	}
+
+	if origin, ok := OriginOf(err); ok {
+		d.Origin = &origin
+	}
+
+	for i, stack := range Stacks(err) {
+		frames := describeStack(stack)
+		if i == 0 {
+			d.Stack = frames
+			continue
+		}
+		d.Rethrown = append(d.Rethrown, frames)
+	}
+
+	return d
+}
+
+func describeStack(stack StackTrace) []FrameDetails {
+	frames := make([]FrameDetails, 0, len(stack))
+	for _, f := range stack {
+		frames = append(frames, FrameDetails{Function: f.name(), File: f.file(), Line: f.line()})
+	}
+
+	return frames
+}
+
+// ComputeFingerprint computes the fingerprint of d the same way Fingerprint does
+// for errors. It is useful for Details which were not produced by Describe.
+func (d *Details) ComputeFingerprint() string {
+	functions := make([]string, 0, len(d.Stack))
+	for _, f := range d.Stack {
+		functions = append(functions, f.Function)
+	}
+
+	message := d.Message
+	if len(d.Chain) > 0 {
+		message = d.Chain[len(d.Chain)-1]
+	}
+
+	return fingerprint(d.Components, functions, message)
+}
+
+func MarshalJSON(err error) ([]byte, error) {
+	return json.Marshal(Describe(err))
+}
+
+// chainMessages lists messages of the unwrap chain, skipping wrappers which
+// do not change the message. It is empty when there is only one message.
+func chainMessages(err error) []string {
+	var chain []string
+	for ; err != nil; err = errors.Unwrap(err) {
+		msg := err.Error()
+		if len(chain) == 0 || chain[len(chain)-1] != msg {
+			chain = append(chain, msg)
+		}
+	}
+
+	if len(chain) < 2 {
+		return nil
+	}
+
+	return chain
+}
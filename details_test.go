@@ -0,0 +1,96 @@
+package cerrors
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDescribe(t *testing.T) {
+	t.Run("nil", func(t *testing.T) {
+		if Describe(nil) != nil {
+			t.Error("not nil details")
+		}
+	})
+
+	t.Run("ordinal error", func(t *testing.T) {
+		d := Describe(errors.New("err"))
+
+		expected := &Details{Message: "err", Fingerprint: Fingerprint(errors.New("err"))}
+		if !reflect.DeepEqual(expected, d) {
+			t.Errorf("unexpected details: expected %+v, got %+v", expected, d)
+		}
+	})
+
+	t.Run("enriched error", func(t *testing.T) {
+		ctx := InComponent(context.Background(), "api")
+		ctx = WithCtxField(ctx, "userId", 11)
+
+		err := Opaque("internal error", WithKind(WithCode(Enrich(ctx, errors.New("err")), "db.error"), KindUnavailable))
+		d := Describe(err)
+
+		if d.Message != "internal error" || d.Code != "db.error" || d.Kind != "unavailable" {
+			t.Errorf("unexpected details: %+v", d)
+		}
+
+		expectedChain := []string{"internal error", "err"}
+		if !reflect.DeepEqual(expectedChain, d.Chain) {
+			t.Errorf("unexpected chain: expected %v, got %v", expectedChain, d.Chain)
+		}
+
+		if !reflect.DeepEqual([]string{"api"}, d.Components) {
+			t.Errorf("unexpected components: %v", d.Components)
+		}
+
+		if !reflect.DeepEqual(map[string]any{"userId": 11}, d.Fields) {
+			t.Errorf("unexpected fields: %v", d.Fields)
+		}
+
+		if len(d.Stack) == 0 || !strings.HasSuffix(d.Stack[0].Function, "TestDescribe.func3") || d.Stack[0].Line == 0 {
+			t.Errorf("unexpected stack: %+v", d.Stack)
+		}
+	})
+}
+
+func TestMarshalJSON(t *testing.T) {
+	err := WithCode(WithField(errors.New("err"), "userId", 11), "user.not_found")
+
+	b, mErr := MarshalJSON(err)
+	if mErr != nil {
+		t.Fatal(mErr)
+	}
+
+	var got map[string]any
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]any{
+		"message":     "err",
+		"code":        "user.not_found",
+		"fields":      map[string]any{"userId": float64(11)},
+		"fingerprint": Fingerprint(err),
+	}
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("unexpected json: expected %v, got %v", expected, got)
+	}
+}
+
+func TestComputeFingerprint(t *testing.T) {
+	ctx := InComponent(context.Background(), "api")
+	errs := []error{
+		errors.New("err"),
+		Opaque("internal error", errors.New("err")),
+		Enrich(ctx, errors.New("err")),
+	}
+
+	for _, err := range errs {
+		d := Describe(err)
+		if d.ComputeFingerprint() != d.Fingerprint {
+			t.Errorf("unexpected fingerprint of %q: expected %v, got %v", err, d.Fingerprint, d.ComputeFingerprint())
+		}
+	}
+}
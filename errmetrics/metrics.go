@@ -0,0 +1,186 @@
+// Package errmetrics counts reported errors by component path, code, kind and
+// fingerprint bucket. Metrics is both a prometheus.Collector and an expvar.Var.
+package errmetrics
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/sloory/cerrors"
+)
+
+const (
+	defaultMaxValues = 100
+	defaultBuckets   = 16
+
+	// OverflowValue replaces label values above the Options.MaxValues limit.
+	OverflowValue = "other"
+)
+
+var labelNames = []string{"component", "code", "kind", "fingerprint"}
+
+type Options struct {
+	// Namespace prefixes the metric name, "cerrors" by default.
+	Namespace string
+	// MaxValues bounds the number of distinct values of every label, 100 by default.
+	MaxValues int
+	// FingerprintBuckets is the number of buckets fingerprints are spread into, 16 by default.
+	FingerprintBuckets int
+}
+
+type labels struct {
+	component, code, kind, fingerprint string
+}
+
+type Metrics struct {
+	desc      *prometheus.Desc
+	maxValues int
+	buckets   uint32
+
+	mu     sync.Mutex
+	counts map[labels]uint64
+	seen   [4]map[string]struct{}
+}
+
+// check interface implementation
+var _ prometheus.Collector = (*Metrics)(nil)
+
+func New(opts Options) *Metrics {
+	if opts.Namespace == "" {
+		opts.Namespace = "cerrors"
+	}
+	if opts.MaxValues <= 0 {
+		opts.MaxValues = defaultMaxValues
+	}
+	if opts.FingerprintBuckets <= 0 {
+		opts.FingerprintBuckets = defaultBuckets
+	}
+
+	m := &Metrics{
+		desc: prometheus.NewDesc(
+			prometheus.BuildFQName(opts.Namespace, "", "errors_total"),
+			"Number of reported errors.",
+			labelNames, nil,
+		),
+		maxValues: opts.MaxValues,
+		buckets:   uint32(opts.FingerprintBuckets),
+		counts:    make(map[labels]uint64),
+	}
+	for i := range m.seen {
+		m.seen[i] = make(map[string]struct{})
+	}
+
+	return m
+}
+
+// Observe counts err, call it from a reporter or a logging middleware.
+func (m *Metrics) Observe(err error) {
+	if err == nil {
+		return
+	}
+
+	m.add(cerrors.Components(err), cerrors.Code(err), cerrors.KindOf(err), cerrors.Fingerprint(err))
+}
+
+// Hook counts an error once, when it is enriched for the first time with a context
+// carrying the hook. The error is marked as counted and otherwise left unchanged.
+func (m *Metrics) Hook() cerrors.EnrichHook {
+	return func(ctx context.Context, e *cerrors.Enrichment) {
+		if errors.Is(e.Err, &countedError{metrics: m}) {
+			return
+		}
+
+		e.Err = &countedError{cause: e.Err, metrics: m}
+		e.OnEnriched = append(e.OnEnriched, m.Observe)
+	}
+}
+
+// countedError marks an error counted by the Hook of metrics.
+type countedError struct {
+	cause   error
+	metrics *Metrics
+}
+
+func (c *countedError) Error() string { return c.cause.Error() }
+func (c *countedError) Unwrap() error { return c.cause }
+func (c *countedError) Format(f fmt.State, verb rune) {
+	fmt.Fprintf(f, fmt.FormatString(f, verb), c.cause)
+}
+
+func (c *countedError) Is(target error) bool {
+	t, ok := target.(*countedError)
+	return ok && t.metrics == c.metrics
+}
+
+func (m *Metrics) add(components []string, code string, kind cerrors.Kind, fingerprint string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	l := labels{
+		component:   m.bound(0, strings.Join(components, "/")),
+		code:        m.bound(1, code),
+		kind:        m.bound(2, kind.String()),
+		fingerprint: m.bound(3, m.bucket(fingerprint)),
+	}
+	m.counts[l]++
+}
+
+// bound returns value or OverflowValue when label already has too many values.
+func (m *Metrics) bound(label int, value string) string {
+	if _, ok := m.seen[label][value]; ok {
+		return value
+	}
+
+	if len(m.seen[label]) >= m.maxValues {
+		return OverflowValue
+	}
+
+	m.seen[label][value] = struct{}{}
+	return value
+}
+
+func (m *Metrics) bucket(fingerprint string) string {
+	h := fnv.New32a()
+	h.Write([]byte(fingerprint))
+
+	return strconv.FormatUint(uint64(h.Sum32()%m.buckets), 10)
+}
+
+func (m *Metrics) Describe(ch chan<- *prometheus.Desc) {
+	ch <- m.desc
+}
+
+func (m *Metrics) Collect(ch chan<- prometheus.Metric) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for l, count := range m.counts {
+		ch <- prometheus.MustNewConstMetric(
+			m.desc, prometheus.CounterValue, float64(count),
+			l.component, l.code, l.kind, l.fingerprint,
+		)
+	}
+}
+
+// String implements expvar.Var, counts are keyed by
+// "component=...,code=...,kind=...,fingerprint=...".
+func (m *Metrics) String() string {
+	m.mu.Lock()
+	counts := make(map[string]uint64, len(m.counts))
+	for l, count := range m.counts {
+		key := "component=" + l.component + ",code=" + l.code + ",kind=" + l.kind + ",fingerprint=" + l.fingerprint
+		counts[key] = count
+	}
+	m.mu.Unlock()
+
+	b, _ := json.Marshal(counts)
+	return string(b)
+}
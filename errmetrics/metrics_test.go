@@ -0,0 +1,133 @@
+package errmetrics
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"expvar"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/sloory/cerrors"
+)
+
+func TestObserve(t *testing.T) {
+	t.Run("labels", func(t *testing.T) {
+		m := New(Options{FingerprintBuckets: 1})
+
+		ctx := cerrors.InComponent(context.Background(), "api")
+		ctx = cerrors.InComponent(ctx, "storage")
+
+		m.Observe(cerrors.WithCode(cerrors.Enrich(ctx, errors.New("err")), "db.timeout"))
+		m.Observe(cerrors.WithCode(cerrors.Enrich(ctx, errors.New("err")), "db.timeout"))
+		m.Observe(cerrors.WithKind(errors.New("err"), cerrors.KindNotFound))
+		m.Observe(nil)
+
+		expected := `
+# HELP cerrors_errors_total Number of reported errors.
+# TYPE cerrors_errors_total counter
+cerrors_errors_total{code="",component="",fingerprint="0",kind="not_found"} 1
+cerrors_errors_total{code="db.timeout",component="api/storage",fingerprint="0",kind="unknown"} 2
+`
+		if err := testutil.CollectAndCompare(m, strings.NewReader(expected)); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("bounded cardinality", func(t *testing.T) {
+		m := New(Options{Namespace: "app", MaxValues: 2, FingerprintBuckets: 1})
+
+		for _, code := range []string{"a", "b", "c", "d"} {
+			m.Observe(cerrors.WithCode(errors.New("err"), code))
+		}
+
+		expected := `
+# HELP app_errors_total Number of reported errors.
+# TYPE app_errors_total counter
+app_errors_total{code="a",component="",fingerprint="0",kind="unknown"} 1
+app_errors_total{code="b",component="",fingerprint="0",kind="unknown"} 1
+app_errors_total{code="other",component="",fingerprint="0",kind="unknown"} 2
+`
+		if err := testutil.CollectAndCompare(m, strings.NewReader(expected)); err != nil {
+			t.Error(err)
+		}
+	})
+}
+
+func TestHook(t *testing.T) {
+	t.Run("counted once", func(t *testing.T) {
+		m := New(Options{FingerprintBuckets: 1})
+
+		ctx := cerrors.InComponent(context.Background(), "api")
+		ctx = cerrors.WithEnrichHook(ctx, m.Hook())
+
+		err := cerrors.Enrich(ctx, errors.New("err"))
+		_ = cerrors.Enrich(ctx, err)
+
+		expected := `
+# HELP cerrors_errors_total Number of reported errors.
+# TYPE cerrors_errors_total counter
+cerrors_errors_total{code="",component="api",fingerprint="0",kind="unknown"} 1
+`
+		if err := testutil.CollectAndCompare(m, strings.NewReader(expected)); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("error with stack", func(t *testing.T) {
+		m := New(Options{FingerprintBuckets: 1})
+		ctx := cerrors.WithEnrichHook(context.Background(), m.Hook())
+
+		err := cerrors.Enrich(ctx, cerrors.WithStack(errors.New("err")))
+		_ = cerrors.Enrich(ctx, err)
+
+		if count := testutil.ToFloat64(m); count != 1 {
+			t.Errorf("unexpected count: expected %v, got %v", 1, count)
+		}
+	})
+
+	t.Run("skip stack", func(t *testing.T) {
+		m := New(Options{FingerprintBuckets: 1})
+		ctx := cerrors.WithConfig(context.Background(), cerrors.Config{SkipStack: true})
+		ctx = cerrors.WithEnrichHook(ctx, m.Hook())
+
+		err := cerrors.Enrich(ctx, errors.New("err"))
+		err = cerrors.Enrich(ctx, err)
+		_ = cerrors.Enrich(ctx, err)
+
+		if count := testutil.ToFloat64(m); count != 1 {
+			t.Errorf("unexpected count: expected %v, got %v", 1, count)
+		}
+	})
+
+	t.Run("same labels as observe", func(t *testing.T) {
+		hooked, observed := New(Options{}), New(Options{})
+		ctx := cerrors.InComponent(context.Background(), "api")
+		ctx = cerrors.WithEnrichHook(ctx, hooked.Hook())
+
+		observed.Observe(cerrors.Enrich(ctx, errors.New("err")))
+
+		if hooked.String() != observed.String() {
+			t.Errorf("unexpected counts: expected %v, got %v", observed.String(), hooked.String())
+		}
+	})
+}
+
+func TestString(t *testing.T) {
+	m := New(Options{FingerprintBuckets: 1})
+	m.Observe(cerrors.WithCode(errors.New("err"), "code"))
+
+	var _ expvar.Var = m
+
+	var counts map[string]uint64
+	if err := json.Unmarshal([]byte(m.String()), &counts); err != nil {
+		t.Fatal(err)
+	}
+
+	key := "component=,code=code,kind=unknown,fingerprint=0"
+	if counts[key] != 1 {
+		t.Errorf("unexpected counts: %v", counts)
+	}
+}
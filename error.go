@@ -4,18 +4,54 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 )
 
 func Enrich(ctx context.Context, err error) error {
+	return enrich(ctx, err, 1)
+}
+
+// enrich is Enrich which captures the stack starting skip frames above its caller.
+func enrich(ctx context.Context, err error, skip int) error {
+	if err == nil {
+		return nil
+	}
+
 	if ctx == nil {
-		return newWithStack(err)
+		ctx = context.Background()
+	}
+
+	cfg := configFrom(ctx)
+	e := &Enrichment{
+		Err:        err,
+		Fields:     CtxFields(ctx),
+		Components: getCtxComponents(ctx),
+		SkipStack:  cfg.SkipStack,
+	}
+	runHooks(ctx, cfg, e)
+	if e.Err != nil {
+		err = e.Err
+	}
+
+	if !e.SkipStack {
+		err = newWithStack(cfg, err, 1+skip)
 	}
 
-	return enrichWithComponents(ctx, enrichWithFields(ctx, newWithStack(err)))
+	err = enrichWithFields(cfg, err, e.Fields)
+	err = enrichWithComponents(err, e.Components)
+	if e.Code != "" && Code(err) != e.Code {
+		err = newWithCode(err, e.Code)
+	}
+
+	err = enrichWithBreadcrumbs(ctx, err)
+	err = enrichWithOrigin(ctx, err)
+
+	runOnEnriched(e, err)
+	return err
 }
 
 func WithStack(err error) error {
-	return newWithStack(err)
+	return newWithStack(currentConfig(), err, 1)
 }
 
 func Wrap(msg string, err error) error {
@@ -39,23 +75,33 @@ func Nested(parent, child error) error {
 }
 
 func WithField(err error, key string, value any) error {
+	return WithFieldContext(context.Background(), err, key, value)
+}
+
+func WithFields(err error, fields map[string]any) error {
+	return WithFieldsContext(context.Background(), err, fields)
+}
+
+// WithFieldContext is WithField which applies the configuration of ctx set with WithConfig.
+func WithFieldContext(ctx context.Context, err error, key string, value any) error {
 	if err == nil {
 		return nil
 	}
 
-	fErr := newWithFields(err)
-	fErr.AddField(key, value)
-	return fErr
+	fErr, err := newWithFields(err)
+	mergeFields(configFrom(ctx), fErr, map[string]any{key: value})
+	return err
 }
 
-func WithFields(err error, fields map[string]any) error {
+// WithFieldsContext is WithFields which applies the configuration of ctx set with WithConfig.
+func WithFieldsContext(ctx context.Context, err error, fields map[string]any) error {
 	if err == nil {
 		return nil
 	}
 
-	fErr := newWithFields(err)
-	fErr.AddFields(fields)
-	return fErr
+	fErr, err := newWithFields(err)
+	mergeFields(configFrom(ctx), fErr, fields)
+	return err
 }
 
 func Fields(err error) map[string]any {
@@ -83,3 +129,45 @@ func Components(err error) []string {
 
 	return nil
 }
+
+func Breadcrumbs(err error) []Crumb {
+	if err == nil {
+		return nil
+	}
+
+	var bErr withBreadcrumbs
+	if errors.As(err, &bErr) {
+		return bErr.Breadcrumbs()
+	}
+
+	return nil
+}
+
+func Time(err error) time.Time {
+	origin, _ := OriginOf(err)
+	return origin.Time
+}
+
+func OriginOf(err error) (Origin, bool) {
+	if err == nil {
+		return Origin{}, false
+	}
+
+	var oErr withOrigin
+	if errors.As(err, &oErr) {
+		return oErr.Origin(), true
+	}
+
+	return Origin{}, false
+}
+
+// Stack returns the deepest stack in the tree of err, which is the closest one to
+// where the error happened. Errors joined with errors.Join are looked into as well.
+func Stack(err error) StackTrace {
+	all := stacks(err)
+	if len(all) == 0 {
+		return nil
+	}
+
+	return all[len(all)-1]
+}
@@ -3,6 +3,7 @@ package cerrors
 import (
 	"context"
 	"errors"
+	"fmt"
 	"reflect"
 	"testing"
 )
@@ -157,6 +158,20 @@ func TestOpaque(t *testing.T) {
 			t.Errorf("unexpected error message: expected %v, got %v", expected, err.Error())
 		}
 	})
+
+	t.Run("format", func(t *testing.T) {
+		err := Opaque("user not found", errors.New("record not found"))
+
+		expected := "user not found"
+		if fmt.Sprintf("%v", err) != expected {
+			t.Errorf("unexpected error message: expected %v, got %v", expected, fmt.Sprintf("%v", err))
+		}
+
+		expected = "user not found\nrecord not found"
+		if fmt.Sprintf("%+v", err) != expected {
+			t.Errorf("unexpected error message: expected %v, got %v", expected, fmt.Sprintf("%+v", err))
+		}
+	})
 }
 
 func TestInComponent(t *testing.T) {
@@ -231,6 +246,23 @@ func TestWithField(t *testing.T) {
 }
 
 func TestFields(t *testing.T) {
+	t.Run("fields added to enriched error", func(t *testing.T) {
+		err := Enrich(context.Background(), errors.New("err"))
+		err = WithField(err, "key", "value")
+
+		requireStack(t, err)
+		requireFields(t, err, map[string]any{"key": "value"})
+	})
+
+	t.Run("enriched error keeps stack", func(t *testing.T) {
+		ctx := WithCtxField(context.Background(), "requestId", 1)
+
+		err := Enrich(ctx, WithField(errors.New("err"), "key", "value"))
+
+		requireStack(t, err)
+		requireFields(t, err, map[string]any{"key": "value", "requestId": 1})
+	})
+
 	t.Run("ordinal error", func(t *testing.T) {
 		err := Fields(errors.New("err"))
 		if err != nil {
@@ -258,6 +290,117 @@ func TestFields(t *testing.T) {
 	})
 }
 
+func TestCode(t *testing.T) {
+	t.Run("nil", func(t *testing.T) {
+		if WithCode(nil, "code") != nil {
+			t.Error("not nil error")
+		}
+	})
+
+	t.Run("ordinal error", func(t *testing.T) {
+		if Code(errors.New("err")) != "" {
+			t.Error("not empty code")
+		}
+	})
+
+	t.Run("outermost code", func(t *testing.T) {
+		initialError := errors.New("err")
+		err := WithCode(WithCode(initialError, "db.error"), "user.not_found")
+
+		if !errors.Is(err, initialError) {
+			t.Error("do not match initial error")
+		}
+
+		expected := "user.not_found"
+		if Code(err) != expected {
+			t.Errorf("unexpected code: expected %v, got %v", expected, Code(err))
+		}
+	})
+}
+
+func TestKindOf(t *testing.T) {
+	t.Run("nil", func(t *testing.T) {
+		if WithKind(nil, KindNotFound) != nil {
+			t.Error("not nil error")
+		}
+	})
+
+	t.Run("ordinal error", func(t *testing.T) {
+		if KindOf(errors.New("err")) != KindUnknown {
+			t.Error("not unknown kind")
+		}
+	})
+
+	t.Run("outermost kind", func(t *testing.T) {
+		err := WithKind(WithKind(errors.New("err"), KindUnavailable), KindNotFound)
+
+		if KindOf(err) != KindNotFound {
+			t.Errorf("unexpected kind: expected %v, got %v", KindNotFound, KindOf(err))
+		}
+	})
+
+	t.Run("parse", func(t *testing.T) {
+		for k := KindUnknown; k <= KindInternal; k++ {
+			if ParseKind(k.String()) != k {
+				t.Errorf("unexpected kind: expected %v, got %v", k, ParseKind(k.String()))
+			}
+		}
+
+		if ParseKind("missing") != KindUnknown {
+			t.Error("not unknown kind")
+		}
+	})
+}
+
+func TestIsRetryable(t *testing.T) {
+	t.Run("nil", func(t *testing.T) {
+		if WithRetryable(nil, true) != nil {
+			t.Error("not nil error")
+		}
+	})
+
+	t.Run("ordinal error", func(t *testing.T) {
+		if IsRetryable(errors.New("err")) {
+			t.Error("retryable error")
+		}
+	})
+
+	t.Run("outermost mark", func(t *testing.T) {
+		err := WithRetryable(errors.New("err"), true)
+		if !IsRetryable(err) {
+			t.Error("not retryable error")
+		}
+
+		if IsRetryable(WithRetryable(err, false)) {
+			t.Error("retryable error")
+		}
+	})
+}
+
+func TestStack(t *testing.T) {
+	t.Run("nil", func(t *testing.T) {
+		if Stack(nil) != nil {
+			t.Error("not nil stack")
+		}
+	})
+
+	t.Run("ordinal error", func(t *testing.T) {
+		if Stack(errors.New("err")) != nil {
+			t.Error("not nil stack")
+		}
+	})
+
+	t.Run("deepest", func(t *testing.T) {
+		inner := WithStack(errors.New("err"))
+		outer := &withStack{cause: Wrap("wrapped", inner), stack: callers(0, currentConfig())}
+
+		expected := inner.(stackTrace).StackTrace()
+		if !reflect.DeepEqual(expected, Stack(outer)) {
+			t.Errorf("unexpected stack: expected %v, got %v", expected, Stack(outer))
+		}
+	})
+}
+
 func requireStack(t *testing.T, err error) {
 	var errWithStack stackTrace
 	if !errors.As(err, &errWithStack) || len(errWithStack.StackTrace()) == 0 {
@@ -0,0 +1,4 @@
+package errproto
+
+// Requires buf and protoc-gen-go v1.31.0 in PATH.
+//go:generate buf generate
@@ -0,0 +1,10 @@
+module github.com/sloory/cerrors/errproto
+
+go 1.20
+
+replace github.com/sloory/cerrors => ../
+
+require (
+	github.com/sloory/cerrors v0.2.0
+	google.golang.org/protobuf v1.31.0
+)
@@ -0,0 +1,140 @@
+// Package errproto carries enriched errors between services as protobuf Envelope messages.
+//
+// The sender converts errors with ToProto, the receiver rebuilds them with FromProto
+// as a RemoteError, so Code, KindOf, Components and Fields of cerrors keep working.
+package errproto
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"github.com/sloory/cerrors"
+)
+
+func ToProto(err error) *Envelope {
+	d := cerrors.Describe(err)
+	if d == nil {
+		return nil
+	}
+
+	e := &Envelope{
+		Message:     d.Message,
+		Chain:       d.Chain,
+		Code:        d.Code,
+		Kind:        d.Kind,
+		Components:  d.Components,
+		Fingerprint: d.Fingerprint,
+	}
+
+	if len(d.Fields) > 0 {
+		e.Fields = make(map[string]*structpb.Value, len(d.Fields))
+		for k, v := range d.Fields {
+			e.Fields[k] = toValue(v)
+		}
+	}
+
+	for _, f := range d.Stack {
+		e.Stack = append(e.Stack, &Frame{Function: f.Function, File: f.File, Line: int64(f.Line)})
+	}
+
+	return e
+}
+
+// toValue converts v to a protobuf value, degrading values of types
+// structpb does not support to strings.
+func toValue(v any) *structpb.Value {
+	if value, err := structpb.NewValue(v); err == nil {
+		return value
+	}
+
+	switch v := v.(type) {
+	case encoding.TextMarshaler:
+		if text, err := v.MarshalText(); err == nil {
+			return structpb.NewStringValue(string(text))
+		}
+	case fmt.Stringer:
+		return structpb.NewStringValue(v.String())
+	}
+
+	if data, err := json.Marshal(v); err == nil {
+		return structpb.NewStringValue(string(data))
+	}
+
+	return structpb.NewStringValue(fmt.Sprint(v))
+}
+
+// FromProto rebuilds the error received from service. Its components are prefixed with
+// the service name, fields hold values as decoded from JSON, numbers are float64.
+func FromProto(service string, e *Envelope) error {
+	if e == nil {
+		return nil
+	}
+
+	r := &RemoteError{
+		Service:     service,
+		Message:     e.GetMessage(),
+		Chain:       e.GetChain(),
+		Fingerprint: e.GetFingerprint(),
+		code:        e.GetCode(),
+		kind:        cerrors.ParseKind(e.GetKind()),
+		components:  append([]string{service}, e.GetComponents()...),
+	}
+
+	for _, f := range e.GetStack() {
+		r.Stack = append(r.Stack, cerrors.FrameDetails{Function: f.GetFunction(), File: f.GetFile(), Line: int(f.GetLine())})
+	}
+
+	if len(e.GetFields()) == 0 {
+		return r
+	}
+
+	fields := make(map[string]any, len(e.GetFields()))
+	for k, v := range e.GetFields() {
+		fields[k] = v.AsInterface()
+	}
+
+	return cerrors.WithFields(r, fields)
+}
+
+// RemoteError is an error received from another service.
+type RemoteError struct {
+	Service     string
+	Message     string
+	Chain       []string
+	Fingerprint string
+	// Stack is the stack of the remote service, from the innermost frame.
+	Stack []cerrors.FrameDetails
+
+	code       string
+	kind       cerrors.Kind
+	components []string
+}
+
+func (r *RemoteError) Error() string        { return r.Message }
+func (r *RemoteError) Code() string         { return r.code }
+func (r *RemoteError) Kind() cerrors.Kind   { return r.kind }
+func (r *RemoteError) Components() []string { return r.components }
+func (r *RemoteError) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		if s.Flag('+') {
+			io.WriteString(s, r.Message)
+			if len(r.Stack) > 0 {
+				fmt.Fprintf(s, "\nremote stack of %s:", r.Service)
+			}
+			for _, f := range r.Stack {
+				fmt.Fprintf(s, "\n%s\n\t%s:%d", f.Function, f.File, f.Line)
+			}
+			return
+		}
+		io.WriteString(s, r.Message)
+	case 's':
+		io.WriteString(s, r.Message)
+	case 'q':
+		fmt.Fprintf(s, "%q", r.Message)
+	}
+}
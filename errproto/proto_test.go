@@ -0,0 +1,118 @@
+package errproto
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/sloory/cerrors"
+)
+
+type point struct{ X, Y int }
+
+func TestToProto(t *testing.T) {
+	t.Run("nil", func(t *testing.T) {
+		if ToProto(nil) != nil {
+			t.Error("not nil envelope")
+		}
+	})
+
+	t.Run("fields", func(t *testing.T) {
+		err := cerrors.WithFields(errors.New("err"), map[string]any{
+			"int":         42,
+			"string":      "s",
+			"map":         map[string]any{"a": 1},
+			"time":        time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+			"stringer":    net.IPv4(127, 0, 0, 1),
+			"struct":      point{1, 2},
+			"unsupported": complex(1, 2),
+		})
+
+		fields := ToProto(err).GetFields()
+		got := make(map[string]any, len(fields))
+		for k, v := range fields {
+			got[k] = v.AsInterface()
+		}
+
+		expected := map[string]any{
+			"int":         float64(42),
+			"string":      "s",
+			"map":         map[string]any{"a": float64(1)},
+			"time":        "2024-01-02T03:04:05Z",
+			"stringer":    "127.0.0.1",
+			"struct":      map[string]any{"X": float64(1), "Y": float64(2)},
+			"unsupported": "(1+2i)",
+		}
+		if !reflect.DeepEqual(expected, got) {
+			t.Errorf("unexpected fields:\nexpected %v\ngot      %v", expected, got)
+		}
+	})
+}
+
+func TestFromProto(t *testing.T) {
+	t.Run("nil", func(t *testing.T) {
+		if FromProto("users", nil) != nil {
+			t.Error("not nil error")
+		}
+	})
+
+	t.Run("round trip", func(t *testing.T) {
+		ctx := cerrors.InComponent(context.Background(), "repository")
+		sent := cerrors.Enrich(ctx, cerrors.Wrap("get user", errors.New("no rows")))
+		sent = cerrors.WithField(sent, "userId", 42)
+		sent = cerrors.WithKind(cerrors.WithCode(sent, "user.not_found"), cerrors.KindNotFound)
+
+		data, err := proto.Marshal(ToProto(sent))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var e Envelope
+		if err := proto.Unmarshal(data, &e); err != nil {
+			t.Fatal(err)
+		}
+
+		received := FromProto("users", &e)
+
+		if received.Error() != "get user: no rows" {
+			t.Errorf("unexpected message: %s", received)
+		}
+		if cerrors.Code(received) != "user.not_found" {
+			t.Errorf("unexpected code: %s", cerrors.Code(received))
+		}
+		if cerrors.KindOf(received) != cerrors.KindNotFound {
+			t.Errorf("unexpected kind: %v", cerrors.KindOf(received))
+		}
+		if expected := []string{"users", "repository"}; !reflect.DeepEqual(expected, cerrors.Components(received)) {
+			t.Errorf("unexpected components: expected %v, got %v", expected, cerrors.Components(received))
+		}
+		if expected := map[string]any{"userId": float64(42)}; !reflect.DeepEqual(expected, cerrors.Fields(received)) {
+			t.Errorf("unexpected fields: expected %v, got %v", expected, cerrors.Fields(received))
+		}
+
+		var remote *RemoteError
+		if !errors.As(received, &remote) {
+			t.Fatalf("expect remote error: %#v", received)
+		}
+		if remote.Service != "users" || remote.Fingerprint != cerrors.Fingerprint(sent) {
+			t.Errorf("unexpected remote error: %+v", remote)
+		}
+		if expected := []string{"get user: no rows", "no rows"}; !reflect.DeepEqual(expected, remote.Chain) {
+			t.Errorf("unexpected chain: expected %v, got %v", expected, remote.Chain)
+		}
+		if len(remote.Stack) == 0 || !strings.HasSuffix(remote.Stack[0].Function, "errproto.TestFromProto.func2") {
+			t.Errorf("unexpected stack: %v", remote.Stack)
+		}
+
+		out := fmt.Sprintf("%+v", received)
+		if !strings.HasPrefix(out, "get user: no rows\nremote stack of users:\ngithub.com/sloory/cerrors/errproto.TestFromProto.func2\n\t") {
+			t.Errorf("unexpected format:\n%s", out)
+		}
+	})
+}
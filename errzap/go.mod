@@ -0,0 +1,12 @@
+module github.com/sloory/cerrors/errzap
+
+go 1.20
+
+replace github.com/sloory/cerrors => ../
+
+require (
+	github.com/sloory/cerrors v0.2.0
+	go.uber.org/zap v1.26.0
+)
+
+require go.uber.org/multierr v1.10.0 // indirect
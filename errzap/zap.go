@@ -0,0 +1,107 @@
+// Package errzap logs enriched errors with go.uber.org/zap using the schema of
+// cerrors.Details: message, code, kind, components, fields and stack.
+package errzap
+
+import (
+	"sort"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/sloory/cerrors"
+)
+
+// Error is like zap.Error, but logs everything attached to err under the "error" key.
+func Error(err error) zap.Field {
+	return NamedError("error", err)
+}
+
+func NamedError(key string, err error) zap.Field {
+	if err == nil {
+		return zap.Skip()
+	}
+
+	return zap.Object(key, Marshaler(err))
+}
+
+func Marshaler(err error) zapcore.ObjectMarshaler {
+	return errorMarshaler{err: err}
+}
+
+type errorMarshaler struct {
+	err error
+}
+
+func (m errorMarshaler) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	d := cerrors.Describe(m.err)
+	if d == nil {
+		return nil
+	}
+
+	enc.AddString("message", d.Message)
+	if d.Code != "" {
+		enc.AddString("code", d.Code)
+	}
+	if d.Kind != "" {
+		enc.AddString("kind", d.Kind)
+	}
+	if len(d.Components) > 0 {
+		if err := enc.AddArray("components", stringsMarshaler(d.Components)); err != nil {
+			return err
+		}
+	}
+	if len(d.Fields) > 0 {
+		if err := enc.AddObject("fields", fieldsMarshaler(d.Fields)); err != nil {
+			return err
+		}
+	}
+	if len(d.Stack) > 0 {
+		return enc.AddArray("stack", stackMarshaler(d.Stack))
+	}
+
+	return nil
+}
+
+type fieldsMarshaler map[string]any
+
+func (m fieldsMarshaler) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		zap.Any(k, m[k]).AddTo(enc)
+	}
+	return nil
+}
+
+type stringsMarshaler []string
+
+func (m stringsMarshaler) MarshalLogArray(enc zapcore.ArrayEncoder) error {
+	for _, s := range m {
+		enc.AppendString(s)
+	}
+	return nil
+}
+
+type stackMarshaler []cerrors.FrameDetails
+
+func (m stackMarshaler) MarshalLogArray(enc zapcore.ArrayEncoder) error {
+	for _, f := range m {
+		if err := enc.AppendObject(frameMarshaler(f)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type frameMarshaler cerrors.FrameDetails
+
+func (m frameMarshaler) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("function", m.Function)
+	enc.AddString("file", m.File)
+	enc.AddInt("line", m.Line)
+	return nil
+}
@@ -0,0 +1,78 @@
+package errzap
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/sloory/cerrors"
+)
+
+func logEntry(t *testing.T, fields ...zap.Field) map[string]any {
+	t.Helper()
+
+	var b bytes.Buffer
+	core := zapcore.NewCore(zapcore.NewJSONEncoder(zapcore.EncoderConfig{MessageKey: "msg"}), zapcore.AddSync(&b), zap.DebugLevel)
+	zap.New(core).Error("failed", fields...)
+
+	var entry map[string]any
+	if err := json.Unmarshal(b.Bytes(), &entry); err != nil {
+		t.Fatalf("invalid entry %s: %v", b.String(), err)
+	}
+	return entry
+}
+
+func TestError(t *testing.T) {
+	t.Run("nil", func(t *testing.T) {
+		entry := logEntry(t, Error(nil))
+
+		if _, ok := entry["error"]; ok {
+			t.Errorf("unexpected error key: %v", entry)
+		}
+	})
+
+	t.Run("enriched", func(t *testing.T) {
+		ctx := cerrors.InComponent(context.Background(), "users")
+		err := cerrors.Enrich(ctx, errors.New("err"))
+		err = cerrors.WithFields(err, map[string]any{"userId": 42, "name": "John"})
+		err = cerrors.WithKind(cerrors.WithCode(err, "user.not_found"), cerrors.KindNotFound)
+
+		logged := logEntry(t, Error(err))["error"].(map[string]any)
+
+		stack, ok := logged["stack"].([]any)
+		if !ok || len(stack) == 0 {
+			t.Fatalf("expect stack: %v", logged)
+		}
+		frame := stack[0].(map[string]any)
+		if !strings.HasSuffix(frame["function"].(string), "errzap.TestError.func2") || frame["line"].(float64) == 0 {
+			t.Errorf("unexpected frame: %v", frame)
+		}
+		delete(logged, "stack")
+
+		expected := map[string]any{
+			"message":    "err",
+			"code":       "user.not_found",
+			"kind":       "not_found",
+			"components": []any{"users"},
+			"fields":     map[string]any{"userId": float64(42), "name": "John"},
+		}
+		if !reflect.DeepEqual(expected, logged) {
+			t.Errorf("unexpected error:\nexpected %v\ngot      %v", expected, logged)
+		}
+	})
+
+	t.Run("plain", func(t *testing.T) {
+		logged := logEntry(t, NamedError("cause", errors.New("err")))["cause"]
+
+		if expected := map[string]any{"message": "err"}; !reflect.DeepEqual(expected, logged) {
+			t.Errorf("unexpected error: expected %v, got %v", expected, logged)
+		}
+	})
+}
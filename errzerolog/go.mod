@@ -0,0 +1,16 @@
+module github.com/sloory/cerrors/errzerolog
+
+go 1.20
+
+replace github.com/sloory/cerrors => ../
+
+require (
+	github.com/rs/zerolog v1.31.0
+	github.com/sloory/cerrors v0.2.0
+)
+
+require (
+	github.com/mattn/go-colorable v0.1.13 // indirect
+	github.com/mattn/go-isatty v0.0.19 // indirect
+	golang.org/x/sys v0.12.0 // indirect
+)
@@ -0,0 +1,79 @@
+// Package errzerolog logs enriched errors with github.com/rs/zerolog using the schema
+// of cerrors.Details: message, code, kind, components, fields and stack.
+//
+// Either log errors with Object:
+//
+//	log.Error().Object("error", errzerolog.Object(err)).Msg("failed")
+//
+// or make every Err call use it:
+//
+//	zerolog.ErrorMarshalFunc = errzerolog.MarshalError
+//	zerolog.ErrorStackMarshaler = errzerolog.MarshalStack
+package errzerolog
+
+import (
+	"github.com/rs/zerolog"
+
+	"github.com/sloory/cerrors"
+)
+
+func Object(err error) zerolog.LogObjectMarshaler {
+	return errorMarshaler{err: err}
+}
+
+// MarshalError is a zerolog.ErrorMarshalFunc, the stack is left to MarshalStack
+// so it is logged only with Event.Stack.
+func MarshalError(err error) interface{} {
+	if err == nil {
+		return nil
+	}
+
+	return errorMarshaler{err: err, skipStack: true}
+}
+
+// MarshalStack is a zerolog.ErrorStackMarshaler.
+func MarshalStack(err error) interface{} {
+	d := cerrors.Describe(err)
+	if d == nil || len(d.Stack) == 0 {
+		return nil
+	}
+
+	return d.Stack
+}
+
+type errorMarshaler struct {
+	err       error
+	skipStack bool
+}
+
+func (m errorMarshaler) MarshalZerologObject(e *zerolog.Event) {
+	d := cerrors.Describe(m.err)
+	if d == nil {
+		return
+	}
+
+	e.Str("message", d.Message)
+	if d.Code != "" {
+		e.Str("code", d.Code)
+	}
+	if d.Kind != "" {
+		e.Str("kind", d.Kind)
+	}
+	if len(d.Components) > 0 {
+		e.Strs("components", d.Components)
+	}
+	if len(d.Fields) > 0 {
+		e.Dict("fields", zerolog.Dict().Fields(d.Fields))
+	}
+	if len(d.Stack) > 0 && !m.skipStack {
+		e.Array("stack", stackMarshaler(d.Stack))
+	}
+}
+
+type stackMarshaler []cerrors.FrameDetails
+
+func (m stackMarshaler) MarshalZerologArray(a *zerolog.Array) {
+	for _, f := range m {
+		a.Dict(zerolog.Dict().Str("function", f.Function).Str("file", f.File).Int("line", f.Line))
+	}
+}
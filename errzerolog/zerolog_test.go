@@ -0,0 +1,109 @@
+package errzerolog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+
+	"github.com/sloory/cerrors"
+)
+
+func enrichedError() error {
+	ctx := cerrors.InComponent(context.Background(), "users")
+	err := cerrors.Enrich(ctx, errors.New("err"))
+	err = cerrors.WithFields(err, map[string]any{"userId": 42, "name": "John"})
+	return cerrors.WithKind(cerrors.WithCode(err, "user.not_found"), cerrors.KindNotFound)
+}
+
+var expectedError = map[string]any{
+	"message":    "err",
+	"code":       "user.not_found",
+	"kind":       "not_found",
+	"components": []any{"users"},
+	"fields":     map[string]any{"userId": float64(42), "name": "John"},
+}
+
+func logEntry(t *testing.T, log func(l zerolog.Logger)) map[string]any {
+	t.Helper()
+
+	var b bytes.Buffer
+	log(zerolog.New(&b))
+
+	var entry map[string]any
+	if err := json.Unmarshal(b.Bytes(), &entry); err != nil {
+		t.Fatalf("invalid entry %s: %v", b.String(), err)
+	}
+	return entry
+}
+
+func requireStack(t *testing.T, stack any) {
+	t.Helper()
+
+	frames, ok := stack.([]any)
+	if !ok || len(frames) == 0 {
+		t.Fatalf("expect stack: %v", stack)
+	}
+
+	frame := frames[0].(map[string]any)
+	if !strings.HasSuffix(frame["function"].(string), "errzerolog.enrichedError") || frame["line"].(float64) == 0 {
+		t.Errorf("unexpected frame: %v", frame)
+	}
+}
+
+func TestObject(t *testing.T) {
+	logged := logEntry(t, func(l zerolog.Logger) {
+		l.Error().Object("error", Object(enrichedError())).Msg("failed")
+	})["error"].(map[string]any)
+
+	requireStack(t, logged["stack"])
+	delete(logged, "stack")
+
+	if !reflect.DeepEqual(expectedError, logged) {
+		t.Errorf("unexpected error:\nexpected %v\ngot      %v", expectedError, logged)
+	}
+}
+
+func TestMarshalers(t *testing.T) {
+	errorMarshalFunc, errorStackMarshaler := zerolog.ErrorMarshalFunc, zerolog.ErrorStackMarshaler
+	zerolog.ErrorMarshalFunc, zerolog.ErrorStackMarshaler = MarshalError, MarshalStack
+	t.Cleanup(func() {
+		zerolog.ErrorMarshalFunc, zerolog.ErrorStackMarshaler = errorMarshalFunc, errorStackMarshaler
+	})
+
+	t.Run("with stack", func(t *testing.T) {
+		entry := logEntry(t, func(l zerolog.Logger) {
+			l.Error().Stack().Err(enrichedError()).Msg("failed")
+		})
+
+		requireStack(t, entry[zerolog.ErrorStackFieldName])
+		if !reflect.DeepEqual(expectedError, entry[zerolog.ErrorFieldName]) {
+			t.Errorf("unexpected error:\nexpected %v\ngot      %v", expectedError, entry[zerolog.ErrorFieldName])
+		}
+	})
+
+	t.Run("without stack", func(t *testing.T) {
+		entry := logEntry(t, func(l zerolog.Logger) {
+			l.Error().Err(enrichedError()).Msg("failed")
+		})
+
+		if _, ok := entry[zerolog.ErrorStackFieldName]; ok {
+			t.Errorf("unexpected stack: %v", entry)
+		}
+	})
+
+	t.Run("nil", func(t *testing.T) {
+		entry := logEntry(t, func(l zerolog.Logger) {
+			l.Error().Stack().Err(nil).Msg("failed")
+		})
+
+		if _, ok := entry[zerolog.ErrorFieldName]; ok {
+			t.Errorf("unexpected error: %v", entry)
+		}
+	})
+}
@@ -22,17 +22,27 @@ type withFieldsError struct {
 	fields map[string]interface{}
 }
 
-func newWithFields(err error) withFields {
-	if err == nil {
-		return nil
-	}
-
+// newWithFields returns the fields holder of err, wrapping err into a new one when
+// there is none in the chain yet, and the error to return to the caller.
+func newWithFields(err error) (withFields, error) {
 	var fErr *withFieldsError
 	if errors.As(err, &fErr) {
-		return fErr
+		return fErr, err
 	}
 
-	return &withFieldsError{cause: err, fields: make(map[string]interface{})}
+	fErr = &withFieldsError{cause: err, fields: make(map[string]interface{})}
+	return fErr, fErr
+}
+
+func mergeFields(cfg *Config, fErr withFields, fields map[string]interface{}) {
+	existing := fErr.Fields()
+	for name, value := range fields {
+		if _, ok := existing[name]; ok && cfg.FieldMerge == MergeKeepExisting {
+			continue
+		}
+
+		fErr.AddField(name, cfg.redact(name, value))
+	}
 }
 
 func (w *withFieldsError) AddField(name string, value any) {
@@ -49,5 +59,5 @@ func (w *withFieldsError) Error() string                  { return w.cause.Error
 func (w *withFieldsError) Unwrap() error                  { return w.cause }
 func (w *withFieldsError) Fields() map[string]interface{} { return w.fields }
 func (w *withFieldsError) Format(f fmt.State, verb rune) {
-	fmt.Printf(fmt.FormatString(f, verb), w.cause)
+	fmt.Fprintf(f, fmt.FormatString(f, verb), w.cause)
 }
@@ -0,0 +1,43 @@
+package cerrors
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// Fingerprint groups errors which happened in the same place. It hashes the
+// components with the functions of the deepest stack, or with the root cause
+// message when err has no stack. Line numbers are left out so fingerprints
+// survive unrelated edits.
+func Fingerprint(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	stack := Stack(err)
+	functions := make([]string, 0, len(stack))
+	for _, f := range stack {
+		functions = append(functions, f.name())
+	}
+
+	return fingerprint(Components(err), functions, Cause(err).Error())
+}
+
+func fingerprint(components, functions []string, message string) string {
+	h := sha256.New()
+	for _, c := range components {
+		h.Write([]byte(c))
+		h.Write([]byte{0})
+	}
+	h.Write([]byte{0})
+
+	if len(functions) == 0 {
+		h.Write([]byte(message))
+	}
+	for _, f := range functions {
+		h.Write([]byte(f))
+		h.Write([]byte{0})
+	}
+
+	return hex.EncodeToString(h.Sum(nil)[:8])
+}
@@ -0,0 +1,50 @@
+package cerrors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestFingerprint(t *testing.T) {
+	t.Run("nil", func(t *testing.T) {
+		if Fingerprint(nil) != "" {
+			t.Error("not empty fingerprint")
+		}
+	})
+
+	newErr := func(ctx context.Context, id int) error {
+		return Enrich(ctx, fmt.Errorf("user %d not found", id))
+	}
+
+	t.Run("same place", func(t *testing.T) {
+		var fingerprints []string
+		for i := 0; i < 2; i++ {
+			fingerprints = append(fingerprints, Fingerprint(newErr(context.Background(), i)))
+		}
+
+		if fingerprints[0] != fingerprints[1] {
+			t.Errorf("expect same fingerprints, got %v", fingerprints)
+		}
+	})
+
+	t.Run("different components", func(t *testing.T) {
+		err1 := newErr(InComponent(context.Background(), "api"), 1)
+		err2 := newErr(InComponent(context.Background(), "worker"), 1)
+
+		if Fingerprint(err1) == Fingerprint(err2) {
+			t.Error("expect different fingerprints")
+		}
+	})
+
+	t.Run("without stack", func(t *testing.T) {
+		if Fingerprint(errors.New("err1")) == Fingerprint(errors.New("err2")) {
+			t.Error("expect different fingerprints")
+		}
+
+		if Fingerprint(errors.New("err")) != Fingerprint(Wrap("wrapped", errors.New("err"))) {
+			t.Error("expect same fingerprints")
+		}
+	})
+}
@@ -0,0 +1,43 @@
+package cerrors_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/sloory/cerrors"
+	"github.com/sloory/cerrors/cerrorstest"
+)
+
+func repository(ctx context.Context) error {
+	ctx = cerrors.InComponent(ctx, "repository")
+
+	return cerrors.Enrich(ctx, cerrors.WithField(errors.New("record not found"), "table", "users"))
+}
+
+func service(ctx context.Context) error {
+	ctx = cerrors.InComponent(ctx, "service")
+	ctx = cerrors.WithCtxField(ctx, "userId", 11)
+
+	return repository(ctx)
+}
+
+func TestFormat(t *testing.T) {
+	t.Run("enriched", func(t *testing.T) {
+		cerrorstest.Snapshot(t, service(context.Background()))
+	})
+
+	t.Run("opaque", func(t *testing.T) {
+		cerrorstest.Snapshot(t, cerrors.Opaque("internal error", service(context.Background())))
+	})
+
+	t.Run("with stack", func(t *testing.T) {
+		cerrorstest.Snapshot(t, cerrors.WithStack(errors.New("ooh")), cerrorstest.KeepLines())
+	})
+
+	t.Run("json", func(t *testing.T) {
+		err := cerrors.Opaque("internal error", cerrors.WithCode(service(context.Background()), "user.not_found"))
+
+		cerrorstest.Snapshot(t, err, cerrorstest.JSON())
+	})
+}
@@ -0,0 +1,98 @@
+package cerrors
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// Group runs tasks in goroutines like golang.org/x/sync/errgroup.Group, but every
+// task gets the components and fields of the group context, its error is enriched
+// with the task label as a component, and Wait returns all errors, not only the first.
+type Group struct {
+	ctx context.Context
+	wg  sync.WaitGroup
+	sem chan struct{}
+
+	mu   sync.Mutex
+	errs []error
+}
+
+func NewGroup(ctx context.Context) *Group {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	return &Group{ctx: ctx}
+}
+
+// SetLimit limits the number of tasks running at once to n, a negative n removes the limit.
+// It must not be called while tasks are running.
+func (g *Group) SetLimit(n int) {
+	if n < 0 {
+		g.sem = nil
+		return
+	}
+
+	g.sem = make(chan struct{}, n)
+}
+
+// Go runs f in a new goroutine, blocking while the limit of running tasks is reached.
+func (g *Group) Go(label string, f func(ctx context.Context) error) {
+	if g.sem != nil {
+		g.sem <- struct{}{}
+	}
+
+	g.mu.Lock()
+	i := len(g.errs)
+	g.errs = append(g.errs, nil)
+	g.mu.Unlock()
+
+	ctx := InComponent(withFieldsCopy(g.ctx), label)
+
+	g.wg.Add(1)
+	go func() {
+		defer g.done()
+
+		if err := f(ctx); err != nil {
+			err = Enrich(ctx, err)
+
+			g.mu.Lock()
+			g.errs[i] = err
+			g.mu.Unlock()
+		}
+	}()
+}
+
+func (g *Group) done() {
+	if g.sem != nil {
+		<-g.sem
+	}
+	g.wg.Done()
+}
+
+// Wait blocks until all tasks are done and joins their errors in the order tasks were started.
+func (g *Group) Wait() error {
+	g.wg.Wait()
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return errors.Join(g.errs...)
+}
+
+// withFieldsCopy gives ctx its own copy of fields, so WithCtxField called
+// from different goroutines does not write to the same map.
+func withFieldsCopy(ctx context.Context) context.Context {
+	fields := CtxFields(ctx)
+	if fields == nil {
+		return ctx
+	}
+
+	fieldsCopy := make(map[string]any, len(fields))
+	for k, v := range fields {
+		fieldsCopy[k] = v
+	}
+
+	return context.WithValue(ctx, fieldsKey, fieldsCopy)
+}
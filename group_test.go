@@ -0,0 +1,113 @@
+package cerrors
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestGroup(t *testing.T) {
+	t.Run("no errors", func(t *testing.T) {
+		g := NewGroup(context.Background())
+		g.Go("task", func(ctx context.Context) error { return nil })
+
+		if err := g.Wait(); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("components of siblings", func(t *testing.T) {
+		ctx := InComponent(context.Background(), "a")
+		ctx = InComponent(ctx, "b")
+		ctx = InComponent(ctx, "c")
+
+		g := NewGroup(ctx)
+		for _, label := range []string{"users", "orders"} {
+			g.Go(label, func(ctx context.Context) error { return errors.New("err") })
+		}
+
+		errs := g.Wait().(interface{ Unwrap() []error }).Unwrap()
+		for i, label := range []string{"users", "orders"} {
+			expected := []string{"a", "b", "c", label}
+			if !reflect.DeepEqual(expected, Components(errs[i])) {
+				t.Errorf("unexpected components: expected %v, got %v", expected, Components(errs[i]))
+			}
+		}
+	})
+
+	t.Run("all errors", func(t *testing.T) {
+		ctx := InComponent(context.Background(), "handler")
+		ctx = WithCtxField(ctx, "requestId", 1)
+
+		err1, err2 := errors.New("err1"), errors.New("err2")
+
+		g := NewGroup(ctx)
+		g.Go("users", func(ctx context.Context) error {
+			time.Sleep(10 * time.Millisecond)
+			return err1
+		})
+		g.Go("orders", func(ctx context.Context) error { return nil })
+		g.Go("payments", func(ctx context.Context) error {
+			return Enrich(WithCtxField(ctx, "paymentId", 2), err2)
+		})
+
+		err := g.Wait()
+		if !errors.Is(err, err1) || !errors.Is(err, err2) {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		errs := err.(interface{ Unwrap() []error }).Unwrap()
+		if len(errs) != 2 {
+			t.Fatalf("unexpected errors count: expected %d, got %d", 2, len(errs))
+		}
+
+		expected := []string{"handler", "users"}
+		if !reflect.DeepEqual(expected, Components(errs[0])) {
+			t.Errorf("unexpected components: expected %v, got %v", expected, Components(errs[0]))
+		}
+		requireFields(t, errs[0], map[string]any{"requestId": 1})
+		requireStack(t, errs[0])
+
+		expected = []string{"handler", "payments"}
+		if !reflect.DeepEqual(expected, Components(errs[1])) {
+			t.Errorf("unexpected components: expected %v, got %v", expected, Components(errs[1]))
+		}
+		requireFields(t, errs[1], map[string]any{"requestId": 1, "paymentId": 2})
+
+		if _, ok := CtxFields(ctx)["paymentId"]; ok {
+			t.Error("task field leaked to group context")
+		}
+	})
+
+	t.Run("limit", func(t *testing.T) {
+		g := NewGroup(context.Background())
+		g.SetLimit(2)
+
+		var running, maxRunning int32
+		for i := 0; i < 10; i++ {
+			g.Go("task", func(ctx context.Context) error {
+				n := atomic.AddInt32(&running, 1)
+				for {
+					m := atomic.LoadInt32(&maxRunning)
+					if n <= m || atomic.CompareAndSwapInt32(&maxRunning, m, n) {
+						break
+					}
+				}
+				time.Sleep(time.Millisecond)
+				atomic.AddInt32(&running, -1)
+				return nil
+			})
+		}
+
+		if err := g.Wait(); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+
+		if maxRunning > 2 {
+			t.Errorf("unexpected running tasks: expected at most %d, got %d", 2, maxRunning)
+		}
+	})
+}
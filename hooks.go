@@ -0,0 +1,99 @@
+package cerrors
+
+import "context"
+
+const (
+	hooksKey key = 5
+)
+
+// Enrichment is what Enrich is going to attach to an error. Hooks may change any of it.
+type Enrichment struct {
+	Err        error
+	Fields     map[string]any
+	Components []string
+	Code       string
+	SkipStack  bool
+	// OnEnriched functions are called with the error Enrich returns,
+	// hooks append to it to see the error with everything attached.
+	OnEnriched []func(err error)
+}
+
+// EnrichHook runs inside Enrich before anything is attached to the error.
+type EnrichHook func(ctx context.Context, e *Enrichment)
+
+// WithEnrichHook adds a hook which runs for errors enriched with ctx,
+// after the global Config.Hooks and previously added context hooks.
+func WithEnrichHook(ctx context.Context, hook EnrichHook) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	hooks := getCtxHooks(ctx)
+	hooks = append(hooks[:len(hooks):len(hooks)], hook)
+
+	return context.WithValue(ctx, hooksKey, hooks)
+}
+
+func getCtxHooks(ctx context.Context) []EnrichHook {
+	hooks, ok := ctx.Value(hooksKey).([]EnrichHook)
+	if !ok {
+		return nil
+	}
+
+	return hooks
+}
+
+func runHooks(ctx context.Context, cfg *Config, e *Enrichment) {
+	ctxHooks := getCtxHooks(ctx)
+	if len(cfg.Hooks) == 0 && len(ctxHooks) == 0 {
+		return
+	}
+
+	for _, hook := range cfg.Hooks {
+		runHook(ctx, hook, e)
+	}
+
+	for _, hook := range ctxHooks {
+		runHook(ctx, hook, e)
+	}
+}
+
+// runOnEnriched calls the OnEnriched functions of e, isolating Enrich from a panicking one.
+func runOnEnriched(e *Enrichment, err error) {
+	for _, f := range e.OnEnriched {
+		func() {
+			defer func() {
+				_ = recover()
+			}()
+
+			f(err)
+		}()
+	}
+}
+
+// runHook isolates Enrich from a panicking hook: the hook works on a copy of e,
+// which is kept only when the hook returns normally.
+func runHook(ctx context.Context, hook EnrichHook, e *Enrichment) {
+	defer func() {
+		_ = recover()
+	}()
+
+	c := e.clone()
+	hook(ctx, c)
+	*e = *c
+}
+
+// clone copies e, so that neither fields and components stored in ctx
+// nor those of e are changed through the copy.
+func (e *Enrichment) clone() *Enrichment {
+	c := *e
+
+	c.Fields = make(map[string]any, len(e.Fields))
+	for k, v := range e.Fields {
+		c.Fields[k] = v
+	}
+	c.Components = append([]string(nil), e.Components...)
+	c.OnEnriched = append(make([]func(error), 0, len(e.OnEnriched)), e.OnEnriched...)
+
+	return &c
+}
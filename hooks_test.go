@@ -0,0 +1,154 @@
+package cerrors
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type tenantKey struct{}
+
+func TestEnrichHook(t *testing.T) {
+	t.Run("add field from own context key", func(t *testing.T) {
+		ctx := WithEnrichHook(context.Background(), func(ctx context.Context, e *Enrichment) {
+			e.Fields["tenantId"] = ctx.Value(tenantKey{})
+		})
+		ctx = context.WithValue(ctx, tenantKey{}, "acme")
+
+		err := Enrich(ctx, errors.New("err"))
+
+		requireFields(t, err, map[string]any{"tenantId": "acme"})
+	})
+
+	t.Run("code and components", func(t *testing.T) {
+		ctx := InComponent(context.Background(), "storage")
+		ctx = WithEnrichHook(ctx, func(ctx context.Context, e *Enrichment) {
+			e.Code = "db.unavailable"
+			e.Components = append(e.Components, "postgres")
+		})
+
+		err := Enrich(ctx, errors.New("err"))
+
+		if Code(err) != "db.unavailable" {
+			t.Errorf("unexpected code: expected %v, got %v", "db.unavailable", Code(err))
+		}
+
+		expected := []string{"storage", "postgres"}
+		if !reflect.DeepEqual(expected, Components(err)) {
+			t.Errorf("unexpected components: expected %v, got %v", expected, Components(err))
+		}
+
+		expected = []string{"storage"}
+		if !reflect.DeepEqual(expected, getCtxComponents(ctx)) {
+			t.Errorf("ctx components changed: expected %v, got %v", expected, getCtxComponents(ctx))
+		}
+	})
+
+	t.Run("veto stack", func(t *testing.T) {
+		ctx := WithEnrichHook(context.Background(), func(ctx context.Context, e *Enrichment) {
+			e.SkipStack = true
+		})
+
+		var stErr stackTrace
+		if errors.As(Enrich(ctx, errors.New("err")), &stErr) {
+			t.Error("expect error without stacktrace")
+		}
+	})
+
+	t.Run("ordered global then context", func(t *testing.T) {
+		var calls []string
+		configureForTest(t, Config{Hooks: []EnrichHook{
+			func(ctx context.Context, e *Enrichment) { calls = append(calls, "global 1") },
+			func(ctx context.Context, e *Enrichment) { calls = append(calls, "global 2") },
+		}})
+
+		ctx := WithEnrichHook(context.Background(), func(ctx context.Context, e *Enrichment) {
+			calls = append(calls, "ctx 1")
+		})
+		ctx = WithEnrichHook(ctx, func(ctx context.Context, e *Enrichment) {
+			calls = append(calls, "ctx 2")
+		})
+
+		_ = Enrich(ctx, errors.New("err"))
+
+		expected := []string{"global 1", "global 2", "ctx 1", "ctx 2"}
+		if !reflect.DeepEqual(expected, calls) {
+			t.Errorf("unexpected calls: expected %v, got %v", expected, calls)
+		}
+	})
+
+	t.Run("panic safe", func(t *testing.T) {
+		ctx := WithEnrichHook(context.Background(), func(ctx context.Context, e *Enrichment) {
+			panic("hook failed")
+		})
+		ctx = WithEnrichHook(ctx, func(ctx context.Context, e *Enrichment) {
+			e.Fields["key"] = "value"
+		})
+
+		err := Enrich(ctx, errors.New("err"))
+
+		requireStack(t, err)
+		requireFields(t, err, map[string]any{"key": "value"})
+	})
+
+	t.Run("panicking hook changes dropped", func(t *testing.T) {
+		ctx := WithEnrichHook(context.Background(), func(ctx context.Context, e *Enrichment) {
+			e.Code = "partial"
+			e.Fields["partial"] = true
+			panic("hook failed")
+		})
+
+		err := Enrich(ctx, errors.New("err"))
+
+		if Code(err) != "" || Fields(err) != nil {
+			t.Errorf("unexpected changes: code %q, fields %v", Code(err), Fields(err))
+		}
+	})
+
+	t.Run("same code - errors wrap not grow", func(t *testing.T) {
+		ctx := WithEnrichHook(context.Background(), func(ctx context.Context, e *Enrichment) {
+			e.Code = "db.unavailable"
+		})
+
+		err := Enrich(ctx, errors.New("err"))
+		for i := 0; i < 3; i++ {
+			err = Enrich(ctx, err)
+		}
+
+		wrapsCount := 0
+		for err := err; err != nil; err = errors.Unwrap(err) {
+			wrapsCount++
+		}
+
+		// original, withStack, withCode
+		expected := 3
+		if wrapsCount != expected {
+			t.Errorf("unexpected wraps: expected %d, got %d", expected, wrapsCount)
+		}
+	})
+
+	t.Run("on enriched", func(t *testing.T) {
+		var enriched error
+		ctx := InComponent(context.Background(), "api")
+		ctx = WithEnrichHook(ctx, func(ctx context.Context, e *Enrichment) {
+			e.OnEnriched = append(e.OnEnriched, func(err error) { enriched = err })
+		})
+
+		err := Enrich(ctx, errors.New("err"))
+
+		if enriched != err {
+			t.Errorf("unexpected error: expected %v, got %v", err, enriched)
+		}
+	})
+
+	t.Run("not called for nil", func(t *testing.T) {
+		ctx := WithEnrichHook(context.Background(), func(ctx context.Context, e *Enrichment) {
+			t.Error("unexpected hook call")
+		})
+
+		if Enrich(ctx, nil) != nil {
+			t.Error("not nil error")
+		}
+	})
+}
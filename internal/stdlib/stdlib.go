@@ -0,0 +1,23 @@
+// Package stdlib tells functions of the Go standard library from the others.
+package stdlib
+
+import "strings"
+
+// Contains reports whether function, a name like those of runtime.Func, belongs to
+// a package of the standard library. Such packages have no dot in the first element
+// of their path, unlike module paths starting with a domain, so the answer does not
+// depend on GOROOT or on file paths trimmed with -trimpath. Functions of modules
+// with no dot in their path, other than package main, are taken for the standard
+// library too.
+func Contains(function string) bool {
+	if i := strings.IndexByte(function, '/'); i >= 0 {
+		return !strings.Contains(function[:i], ".")
+	}
+
+	i := strings.IndexByte(function, '.')
+	if i < 0 {
+		return false
+	}
+
+	return function[:i] != "main"
+}
@@ -0,0 +1,27 @@
+package stdlib
+
+import "testing"
+
+func TestContains(t *testing.T) {
+	tests := []struct {
+		function string
+		expected bool
+	}{
+		{"runtime.goexit", true},
+		{"testing.tRunner.func1", true},
+		{"internal/poll.(*FD).Read", true},
+		{"net/http.(*conn).serve", true},
+		{"main.main", false},
+		{"github.com/sloory/cerrors.Wrap", false},
+		{"github.com/sloory/cerrors_test.TestX.func1", false},
+		{"golang.org/x/sync/errgroup.(*Group).Go.func1", false},
+		{"unknown", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.function, func(t *testing.T) {
+			if got := Contains(tt.function); got != tt.expected {
+				t.Errorf("unexpected result: expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,199 @@
+package cerrors
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"sync"
+)
+
+// stackCallers is implemented by github.com/go-errors/errors and other libraries
+// which keep program counters returned by runtime.Callers.
+type stackCallers interface {
+	Callers() []uintptr
+}
+
+// check interface implementation
+var _ stackCallers = (*withStack)(nil)
+
+// stackOf returns the stack carried by err itself, not looking into its chain.
+func stackOf(err error) (StackTrace, bool) {
+	switch e := err.(type) {
+	case stackTrace:
+		return e.StackTrace(), true
+	case stackCallers:
+		pcs := e.Callers()
+		stack := make(StackTrace, len(pcs))
+		for i, pc := range pcs {
+			stack[i] = Frame(pc)
+		}
+		return stack, true
+	}
+
+	return foreignStackTrace(err)
+}
+
+// carriesStack reports whether err itself carries a stack, like stackOf but
+// without reading the stack.
+func carriesStack(err error) bool {
+	switch err.(type) {
+	case stackTrace, stackCallers:
+		return true
+	}
+
+	return hasForeignStackTrace(err)
+}
+
+// foreignStackTypes caches for every error type seen by hasForeignStackTrace
+// whether it has a foreign StackTrace method.
+var foreignStackTypes sync.Map // reflect.Type -> bool
+
+// hasForeignStackTrace reports whether err has a StackTrace method returning a slice
+// of uintptr based frames, like the errors of github.com/pkg/errors. The method can
+// only be found by reflection: it returns errors.StackTrace, a type of the package
+// of the error, and an interface declared here cannot name it without importing
+// that package.
+func hasForeignStackTrace(err error) bool {
+	typ := reflect.TypeOf(err)
+	if ok, found := foreignStackTypes.Load(typ); found {
+		return ok.(bool)
+	}
+
+	m, ok := typ.MethodByName("StackTrace")
+	if ok {
+		// the receiver is the first argument of a method of a type
+		ft := m.Type
+		ok = ft.NumIn() == 1 && ft.NumOut() == 1 &&
+			ft.Out(0).Kind() == reflect.Slice && ft.Out(0).Elem().Kind() == reflect.Uintptr
+	}
+	foreignStackTypes.Store(typ, ok)
+
+	return ok
+}
+
+// foreignStackTrace returns the stack of an error found by hasForeignStackTrace.
+// Frames of github.com/pkg/errors are program counters + 1 like Frame. The method
+// is called by reflection and its panic, say for a nil pointer receiver, means
+// there is no stack.
+func foreignStackTrace(err error) (stack StackTrace, ok bool) {
+	if !hasForeignStackTrace(err) {
+		return nil, false
+	}
+
+	defer func() {
+		if recover() != nil {
+			stack, ok = nil, false
+		}
+	}()
+
+	st := reflect.ValueOf(err).MethodByName("StackTrace").Call(nil)[0]
+	stack = make(StackTrace, st.Len())
+	for i := range stack {
+		stack[i] = Frame(st.Index(i).Uint())
+	}
+
+	return stack, true
+}
+
+// walk calls f for err and every error of its tree, depth first from the outermost
+// error, following both Unwrap() error and Unwrap() []error, until f returns false.
+func walk(err error, f func(err error) bool) bool {
+	for err != nil {
+		if !f(err) {
+			return false
+		}
+
+		switch e := err.(type) {
+		case interface{ Unwrap() error }:
+			err = e.Unwrap()
+		case interface{ Unwrap() []error }:
+			for _, err := range e.Unwrap() {
+				if !walk(err, f) {
+					return false
+				}
+			}
+			return true
+		default:
+			return true
+		}
+	}
+
+	return true
+}
+
+// stacks returns the stacks of the tree of err in the order walk visits them.
+func stacks(err error) []StackTrace {
+	var res []StackTrace
+	walk(err, func(err error) bool {
+		if st, ok := stackOf(err); ok {
+			res = append(res, st)
+		}
+		return true
+	})
+
+	return res
+}
+
+// hasStack reports whether any error in the tree of err carries a stack, either
+// a known one or one recognized by Config.HasStack.
+func hasStack(cfg *Config, err error) bool {
+	return !walk(err, func(err error) bool {
+		return !carriesStack(err) && (cfg.HasStack == nil || !cfg.HasStack(err))
+	})
+}
+
+// withJoined shows the errors joined with errors.Join in %+v, with their stacks.
+// It is added by WithStack and Enrich, which do not capture a stack of their own
+// when a joined error already has one.
+type withJoined struct {
+	cause error
+}
+
+// joined wraps err into withJoined when it is a bare errors.Join result.
+func joined(err error) error {
+	if _, ok := err.(fmt.Formatter); ok {
+		return err
+	}
+	if _, ok := err.(interface{ Unwrap() []error }); !ok {
+		return err
+	}
+
+	return &withJoined{cause: err}
+}
+
+func (w *withJoined) Error() string { return w.cause.Error() }
+func (w *withJoined) Unwrap() error { return w.cause }
+func (w *withJoined) Format(s fmt.State, verb rune) {
+	if verb != 'v' || !s.Flag('+') {
+		fmt.Fprintf(s, fmt.FormatString(s, verb), w.cause)
+		return
+	}
+
+	for i, err := range w.cause.(interface{ Unwrap() []error }).Unwrap() {
+		if i > 0 {
+			io.WriteString(s, "\n")
+		}
+		fmt.Fprintf(s, "%+v", err)
+	}
+}
+
+// Cause returns the innermost error of the chain like github.com/pkg/errors.Cause,
+// following both Cause and Unwrap methods.
+func Cause(err error) error {
+	for err != nil {
+		var next error
+		switch e := err.(type) {
+		case interface{ Cause() error }:
+			next = e.Cause()
+		case interface{ Unwrap() error }:
+			next = e.Unwrap()
+		}
+
+		if next == nil {
+			return err
+		}
+		err = next
+	}
+
+	return nil
+}
@@ -0,0 +1,203 @@
+package cerrors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// goError mimics github.com/go-errors/errors.Error.
+type goError struct {
+	msg   string
+	stack []uintptr
+}
+
+func (e *goError) Error() string      { return e.msg }
+func (e *goError) Callers() []uintptr { return e.stack }
+
+func newGoError(msg string) error {
+	stack := make([]uintptr, 32)
+	return &goError{msg: msg, stack: stack[:runtime.Callers(1, stack)]}
+}
+
+// pkgFrame and pkgStackTrace mimic the frames of github.com/pkg/errors.
+type pkgFrame uintptr
+
+type pkgStackTrace []pkgFrame
+
+// pkgError mimics the errors of github.com/pkg/errors.
+type pkgError struct {
+	msg   string
+	stack pkgStackTrace
+}
+
+func (e *pkgError) Error() string             { return e.msg }
+func (e *pkgError) StackTrace() pkgStackTrace { return e.stack }
+
+func newPkgError(msg string) error {
+	pcs := make([]uintptr, 32)
+	pcs = pcs[:runtime.Callers(2, pcs)]
+
+	stack := make(pkgStackTrace, len(pcs))
+	for i, pc := range pcs {
+		stack[i] = pkgFrame(pc)
+	}
+
+	return &pkgError{msg: msg, stack: stack}
+}
+
+// valueStackError has a StackTrace method with a value receiver, which panics
+// when it is called through a nil pointer.
+type valueStackError struct{ stack pkgStackTrace }
+
+func (e valueStackError) Error() string             { return "value" }
+func (e valueStackError) StackTrace() pkgStackTrace { return e.stack }
+
+// foreignError mimics an error with a stack of a form cerrors does not know.
+type foreignError struct{ msg string }
+
+func (e *foreignError) Error() string        { return e.msg }
+func (e *foreignError) StackTrace() []string { return nil }
+
+func TestForeignStack(t *testing.T) {
+	t.Run("callers", func(t *testing.T) {
+		initialError := newGoError("err")
+		err := WithStack(Wrap("wrapped", initialError))
+
+		if !errors.Is(err, initialError) {
+			t.Error("do not match initial error")
+		}
+
+		var stErr stackTrace
+		if errors.As(err, &stErr) {
+			t.Error("expect no second stack")
+		}
+
+		stack := Stack(err)
+		if len(stack) == 0 || stack[0].name() != "github.com/sloory/cerrors.newGoError" {
+			t.Errorf("unexpected stack: %v", stack)
+		}
+	})
+
+	t.Run("config", func(t *testing.T) {
+		configureForTest(t, Config{HasStack: func(err error) bool {
+			_, ok := err.(interface{ StackTrace() []string })
+			return ok
+		}})
+
+		err := WithStack(Wrap("wrapped", &foreignError{msg: "err"}))
+
+		var stErr stackTrace
+		if errors.As(err, &stErr) {
+			t.Error("expect no second stack")
+		}
+	})
+
+	t.Run("joined", func(t *testing.T) {
+		err := WithStack(errors.Join(errors.New("err"), newGoError("err")))
+
+		var stErr stackTrace
+		if errors.As(err, &stErr) {
+			t.Error("expect no second stack")
+		}
+
+		stack := Stack(err)
+		if len(stack) == 0 || stack[0].name() != "github.com/sloory/cerrors.newGoError" {
+			t.Errorf("unexpected stack: %v", stack)
+		}
+	})
+
+	t.Run("joined with stack", func(t *testing.T) {
+		err := WithStack(errors.Join(errors.New("a"), WithStack(errors.New("b"))))
+
+		if len(Stack(err)) == 0 || len(Describe(err).Stack) == 0 {
+			t.Fatal("expect stack")
+		}
+
+		formatted := fmt.Sprintf("%+v", err)
+		if !strings.HasPrefix(formatted, "a\nb\ngithub.com/sloory/cerrors.TestForeignStack") {
+			t.Errorf("unexpected format: %q", formatted)
+		}
+	})
+
+	t.Run("pkg/errors", func(t *testing.T) {
+		initialError := newPkgError("err")
+		err := Enrich(context.Background(), Wrap("wrapped", initialError))
+
+		var stErr stackTrace
+		if errors.As(err, &stErr) {
+			t.Error("expect no second stack")
+		}
+
+		stack := Stack(err)
+		if len(stack) == 0 || !strings.HasPrefix(stack[0].name(), "github.com/sloory/cerrors.TestForeignStack") {
+			t.Errorf("unexpected stack: %v", stack)
+		}
+	})
+
+	t.Run("nil value receiver", func(t *testing.T) {
+		var err error = (*valueStackError)(nil)
+
+		if !carriesStack(err) {
+			t.Error("expect a stack method")
+		}
+		if stack, ok := stackOf(err); ok {
+			t.Errorf("unexpected stack: %v", stack)
+		}
+	})
+
+	t.Run("unknown stack trace", func(t *testing.T) {
+		err := WithStack(Wrap("wrapped", &foreignError{msg: "err"}))
+
+		var stErr stackTrace
+		if !errors.As(err, &stErr) {
+			t.Error("expect stack")
+		}
+	})
+
+	t.Run("readable as callers", func(t *testing.T) {
+		err := WithStack(errors.New("err"))
+
+		var cErr interface{ Callers() []uintptr }
+		if !errors.As(err, &cErr) {
+			t.Fatal("expect error with callers")
+		}
+
+		stack := Stack(err)
+		if len(cErr.Callers()) != len(stack) || cErr.Callers()[0] != uintptr(stack[0]) {
+			t.Errorf("unexpected callers: %v", cErr.Callers())
+		}
+	})
+}
+
+type causer struct{ cause error }
+
+func (c *causer) Error() string { return "causer: " + c.cause.Error() }
+func (c *causer) Cause() error  { return c.cause }
+
+func TestCause(t *testing.T) {
+	t.Run("nil", func(t *testing.T) {
+		if Cause(nil) != nil {
+			t.Error("not nil error")
+		}
+	})
+
+	t.Run("ordinal error", func(t *testing.T) {
+		err := errors.New("err")
+		if Cause(err) != err {
+			t.Error("unexpected cause")
+		}
+	})
+
+	t.Run("cause and unwrap", func(t *testing.T) {
+		initialError := errors.New("err")
+		err := Opaque("internal error", &causer{cause: Wrap("wrapped", WithStack(initialError))})
+
+		if Cause(err) != initialError {
+			t.Errorf("unexpected cause: %v", Cause(err))
+		}
+	})
+}
@@ -0,0 +1,98 @@
+package cerrors
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Kind is a coarse category of an error which does not depend on the code producing it.
+type Kind uint8
+
+const (
+	KindUnknown Kind = iota
+	KindInvalid
+	KindNotFound
+	KindConflict
+	KindPermission
+	KindUnauthenticated
+	KindUnavailable
+	KindTimeout
+	KindCanceled
+	KindInternal
+)
+
+var kindNames = [...]string{
+	KindUnknown:         "unknown",
+	KindInvalid:         "invalid",
+	KindNotFound:        "not_found",
+	KindConflict:        "conflict",
+	KindPermission:      "permission",
+	KindUnauthenticated: "unauthenticated",
+	KindUnavailable:     "unavailable",
+	KindTimeout:         "timeout",
+	KindCanceled:        "canceled",
+	KindInternal:        "internal",
+}
+
+func (k Kind) String() string {
+	if int(k) < len(kindNames) {
+		return kindNames[k]
+	}
+
+	return "kind(" + fmt.Sprint(uint8(k)) + ")"
+}
+
+// ParseKind returns the kind with the name, KindUnknown for unknown names.
+func ParseKind(name string) Kind {
+	for k, n := range kindNames {
+		if n == name {
+			return Kind(k)
+		}
+	}
+
+	return KindUnknown
+}
+
+type withKind interface {
+	error
+	fmt.Formatter
+
+	Kind() Kind
+}
+
+// check interface implementation
+var _ withKind = (*withKindError)(nil)
+
+type withKindError struct {
+	cause error
+	kind  Kind
+}
+
+func (w *withKindError) Error() string { return w.cause.Error() }
+func (w *withKindError) Unwrap() error { return w.cause }
+func (w *withKindError) Kind() Kind    { return w.kind }
+func (w *withKindError) Format(f fmt.State, verb rune) {
+	fmt.Fprintf(f, fmt.FormatString(f, verb), w.cause)
+}
+
+func WithKind(err error, kind Kind) error {
+	if err == nil {
+		return nil
+	}
+
+	return &withKindError{cause: err, kind: kind}
+}
+
+// KindOf returns the outermost kind in the chain of err.
+func KindOf(err error) Kind {
+	if err == nil {
+		return KindUnknown
+	}
+
+	var kErr withKind
+	if errors.As(err, &kErr) {
+		return kErr.Kind()
+	}
+
+	return KindUnknown
+}
@@ -0,0 +1,124 @@
+package cerrors
+
+import (
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// AppendLogfmt appends err to dst as logfmt pairs sorted by key:
+//
+//	error="get user: sql: no rows" error.code=user.not_found error.components=api/users
+//	error.field.userId=42 error.kind=not_found error.stack=cerrors.getUser:42,main.main:12
+//
+// Field values are written as Describe returns them: redacted by Config.Redactor when
+// they were attached, then normalized and truncated within the Config limits.
+func AppendLogfmt(dst []byte, err error) []byte {
+	if err == nil {
+		return dst
+	}
+
+	d := Describe(err)
+	pairs := [][2]string{{"error", d.Message}}
+	if d.Code != "" {
+		pairs = append(pairs, [2]string{"error.code", d.Code})
+	}
+	if len(d.Components) > 0 {
+		pairs = append(pairs, [2]string{"error.components", strings.Join(d.Components, "/")})
+	}
+	for _, f := range logfmtFields(d.Fields) {
+		pairs = append(pairs, [2]string{"error.field." + f[0], f[1]})
+	}
+	if d.Kind != "" {
+		pairs = append(pairs, [2]string{"error.kind", d.Kind})
+	}
+	if len(d.Stack) > 0 {
+		frames := make([]string, 0, len(d.Stack))
+		for _, f := range d.Stack {
+			frames = append(frames, path.Base(f.Function)+":"+strconv.Itoa(f.Line))
+		}
+		pairs = append(pairs, [2]string{"error.stack", strings.Join(frames, ",")})
+	}
+
+	sort.SliceStable(pairs, func(i, j int) bool { return pairs[i][0] < pairs[j][0] })
+
+	for i, p := range pairs {
+		if i > 0 {
+			dst = append(dst, ' ')
+		}
+		dst = append(dst, p[0]...)
+		dst = append(dst, '=')
+		dst = appendLogfmtValue(dst, p[1])
+	}
+	return dst
+}
+
+// WriteLogfmt writes err to w as a logfmt line, see AppendLogfmt.
+func WriteLogfmt(w io.Writer, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	_, err = w.Write(append(AppendLogfmt(nil, err), '\n'))
+	return err
+}
+
+// logfmtFields returns fields ordered by their original keys with keys sanitized by
+// logfmtKey. Valid keys are kept as they are, and a sanitized key which collides
+// with another one gets a numeric suffix, so "bad key=" and "bad_key_" become
+// bad_key__2 and bad_key_.
+func logfmtFields(fields map[string]any) [][2]string {
+	keys := make([]string, 0, len(fields))
+	used := make(map[string]bool, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+		if logfmtKey(k) == k {
+			used[k] = true
+		}
+	}
+	sort.Strings(keys)
+
+	pairs := make([][2]string, 0, len(keys))
+	for _, k := range keys {
+		key := logfmtKey(k)
+		if key != k {
+			for n := 2; used[key]; n++ {
+				key = logfmtKey(k) + "_" + strconv.Itoa(n)
+			}
+			used[key] = true
+		}
+		pairs = append(pairs, [2]string{key, fmt.Sprint(fields[k])})
+	}
+	return pairs
+}
+
+// logfmtKey replaces characters not allowed in logfmt keys with underscores.
+func logfmtKey(k string) string {
+	if k == "" {
+		return "_"
+	}
+
+	return strings.Map(func(r rune) rune {
+		if r <= ' ' || r == '=' || r == '"' || r == utf8.RuneError || !unicode.IsPrint(r) {
+			return '_'
+		}
+		return r
+	}, k)
+}
+
+func appendLogfmtValue(dst []byte, v string) []byte {
+	if v != "" && strings.IndexFunc(v, logfmtNeedsQuote) < 0 {
+		return append(dst, v...)
+	}
+
+	return strconv.AppendQuote(dst, v)
+}
+
+func logfmtNeedsQuote(r rune) bool {
+	return r <= ' ' || r == '=' || r == '"' || r == '\\' || r == utf8.RuneError || !unicode.IsPrint(r)
+}
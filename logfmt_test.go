@@ -0,0 +1,82 @@
+package cerrors
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+)
+
+func TestAppendLogfmt(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected string
+	}{
+		{"nil", nil, ""},
+		{"message", errors.New("err"), `error=err`},
+		{"quoted message", errors.New(`get user: "admin"`), `error="get user: \"admin\""`},
+		{"empty message", errors.New(""), `error=""`},
+		{"escaped message", errors.New("line1\nline2\t="), `error="line1\nline2\t="`},
+		{"unicode message", errors.New("ошибка"), `error=ошибка`},
+		{
+			"metadata",
+			WithKind(WithCode(WithFields(errors.New("err"), map[string]any{
+				"userId": 42, "name": "John Smith", "bad key=": "v", "": "empty",
+			}), "user.not_found"), KindNotFound),
+			`error=err error.code=user.not_found error.field._=empty error.field.bad_key_=v ` +
+				`error.field.name="John Smith" error.field.userId=42 error.kind=not_found`,
+		},
+		{
+			"colliding keys",
+			WithFields(errors.New("err"), map[string]any{
+				"bad key=": 1, "bad_key_": 2, "bad\tkey ": 3, "bad_key__2": 4,
+			}),
+			`error=err error.field.bad_key_=2 error.field.bad_key__2=4 error.field.bad_key__3=3 error.field.bad_key__4=1`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(AppendLogfmt(nil, tt.err)); got != tt.expected {
+				t.Errorf("unexpected logfmt:\nexpected %s\ngot      %s", tt.expected, got)
+			}
+		})
+	}
+
+	t.Run("components and stack", func(t *testing.T) {
+		ctx := InComponent(InComponent(context.Background(), "api"), "users")
+		err := Enrich(ctx, errors.New("err"))
+
+		expected := regexp.MustCompile(`^error=err error.components=api/users error.stack=cerrors.TestAppendLogfmt.func\d+:\d+,testing.tRunner:\d+,runtime.goexit:\d+$`)
+		if got := string(AppendLogfmt(nil, err)); !expected.MatchString(got) {
+			t.Errorf("unexpected logfmt: %s", got)
+		}
+	})
+
+	t.Run("redacted", func(t *testing.T) {
+		configureForTest(t, Config{Redactor: func(key string, value any) any {
+			if key == "password" {
+				return "[REDACTED]"
+			}
+			return value
+		}})
+
+		err := WithField(errors.New("err"), "password", "secret")
+
+		if got, expected := string(AppendLogfmt(nil, err)), `error=err error.field.password=[REDACTED]`; got != expected {
+			t.Errorf("unexpected logfmt:\nexpected %s\ngot      %s", expected, got)
+		}
+	})
+}
+
+func TestWriteLogfmt(t *testing.T) {
+	var b bytes.Buffer
+	if err := WriteLogfmt(&b, WithCode(errors.New("err"), "code")); err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := "error=err error.code=code\n"; b.String() != expected {
+		t.Errorf("unexpected output: expected %q, got %q", expected, b.String())
+	}
+}
@@ -0,0 +1,232 @@
+package cerrors
+
+import (
+	"encoding"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"unicode/utf8"
+)
+
+const (
+	defaultMaxFields     = 64
+	defaultMaxFieldBytes = 1024
+	defaultMaxFieldDepth = 5
+
+	// TruncatedField is set to true in Details.Fields when any field was dropped or cut.
+	TruncatedField = "_truncated"
+)
+
+// normalizer turns field values into plain values safe to log: strings, numbers,
+// booleans, nil, []any and map[string]any, bounded by the limits of Config.
+type normalizer struct {
+	maxFields, maxBytes, maxDepth int
+
+	truncated bool
+	// visited are pointers of maps, slices and pointers being normalized, to detect cycles.
+	visited map[uintptr]bool
+}
+
+func newNormalizer(cfg *Config) *normalizer {
+	n := &normalizer{
+		maxFields: cfg.MaxFields,
+		maxBytes:  cfg.MaxFieldBytes,
+		maxDepth:  cfg.MaxFieldDepth,
+		visited:   map[uintptr]bool{},
+	}
+	if n.maxFields <= 0 {
+		n.maxFields = defaultMaxFields
+	}
+	if n.maxBytes <= 0 {
+		n.maxBytes = defaultMaxFieldBytes
+	}
+	if n.maxDepth <= 0 {
+		n.maxDepth = defaultMaxFieldDepth
+	}
+
+	return n
+}
+
+// normalizeFields returns normalized copy of fields, keeping the first Config.MaxFields
+// of them in key order.
+func normalizeFields(cfg *Config, fields map[string]any) map[string]any {
+	if len(fields) == 0 {
+		return nil
+	}
+
+	n := newNormalizer(cfg)
+	normalized := n.normalizeMap(fields, 0)
+	if n.truncated {
+		normalized[TruncatedField] = true
+	}
+
+	return normalized
+}
+
+func (n *normalizer) normalizeMap(m map[string]any, depth int) map[string]any {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	if len(keys) > n.maxFields {
+		keys = keys[:n.maxFields]
+		n.truncated = true
+	}
+
+	normalized := make(map[string]any, len(keys))
+	for _, k := range keys {
+		normalized[k] = n.normalize(m[k], depth)
+	}
+	return normalized
+}
+
+func (n *normalizer) normalize(v any, depth int) any {
+	switch v := v.(type) {
+	case nil, bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, uintptr, float32, float64:
+		return v
+	case string:
+		return n.truncate(v)
+	case []byte:
+		if utf8.Valid(v) {
+			return n.truncate(string(v))
+		}
+		return n.truncate(base64.StdEncoding.EncodeToString(v))
+	case error:
+		return n.truncate(n.call(v.Error))
+	case json.Marshaler:
+		return n.normalizeJSON(v, depth)
+	case encoding.TextMarshaler:
+		return n.truncate(n.call(func() string {
+			text, err := v.MarshalText()
+			if err != nil {
+				return fmt.Sprintf("!ERROR(%v)", err)
+			}
+			return string(text)
+		}))
+	case fmt.Stringer:
+		return n.truncate(n.call(v.String))
+	}
+
+	return n.normalizeValue(reflect.ValueOf(v), depth)
+}
+
+func (n *normalizer) normalizeValue(v reflect.Value, depth int) any {
+	switch v.Kind() {
+	case reflect.Bool:
+		return v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint()
+	case reflect.Float32, reflect.Float64:
+		return v.Float()
+	case reflect.Complex64, reflect.Complex128:
+		return fmt.Sprint(v.Complex())
+	case reflect.String:
+		return n.truncate(v.String())
+	case reflect.Pointer, reflect.Map, reflect.Slice:
+		if v.IsNil() {
+			return nil
+		}
+	case reflect.Array, reflect.Struct:
+	default:
+		return fmt.Sprintf("<%s>", v.Type())
+	}
+
+	if depth >= n.maxDepth {
+		n.truncated = true
+		return fmt.Sprintf("<%s>", v.Type())
+	}
+
+	if v.Kind() == reflect.Pointer || v.Kind() == reflect.Map || v.Kind() == reflect.Slice {
+		ptr := v.Pointer()
+		if n.visited[ptr] {
+			n.truncated = true
+			return "<cycle>"
+		}
+		n.visited[ptr] = true
+		defer delete(n.visited, ptr)
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer:
+		return n.normalize(v.Elem().Interface(), depth)
+	case reflect.Map:
+		m := make(map[string]any, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			m[fmt.Sprint(iter.Key().Interface())] = iter.Value().Interface()
+		}
+		return n.normalizeMap(m, depth+1)
+	case reflect.Slice, reflect.Array:
+		size := v.Len()
+		if size > n.maxFields {
+			size = n.maxFields
+			n.truncated = true
+		}
+		s := make([]any, 0, size)
+		for i := 0; i < size; i++ {
+			s = append(s, n.normalize(v.Index(i).Interface(), depth+1))
+		}
+		return s
+	default: // reflect.Struct, unexported fields are skipped like encoding/json does
+		m := make(map[string]any, v.NumField())
+		for i := 0; i < v.NumField(); i++ {
+			if f := v.Type().Field(i); f.IsExported() {
+				m[f.Name] = v.Field(i).Interface()
+			}
+		}
+		return n.normalizeMap(m, depth+1)
+	}
+}
+
+func (n *normalizer) normalizeJSON(v json.Marshaler, depth int) any {
+	var decoded any
+	data, err := n.marshalJSON(v)
+	if err == nil {
+		err = json.Unmarshal(data, &decoded)
+	}
+	if err != nil {
+		return fmt.Sprintf("!ERROR(%v)", err)
+	}
+
+	return n.normalize(decoded, depth)
+}
+
+func (n *normalizer) marshalJSON(v json.Marshaler) (data []byte, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+
+	return v.MarshalJSON()
+}
+
+// call returns the result of f, which may panic, for example on a nil receiver.
+func (n *normalizer) call(f func() string) (s string) {
+	defer func() {
+		if r := recover(); r != nil {
+			s = fmt.Sprintf("!PANIC(%v)", r)
+		}
+	}()
+
+	return f()
+}
+
+func (n *normalizer) truncate(s string) string {
+	if len(s) <= n.maxBytes {
+		return s
+	}
+
+	n.truncated = true
+	cut := n.maxBytes
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return s[:cut] + "..."
+}
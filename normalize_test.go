@@ -0,0 +1,128 @@
+package cerrors
+
+import (
+	"errors"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type normalizeStatus int
+
+type normalizeUser struct {
+	Name    string
+	Manager *normalizeUser
+	secret  string
+}
+
+type nilStringer struct{ name string }
+
+func (s *nilStringer) String() string { return s.name }
+
+func TestNormalizeFields(t *testing.T) {
+	cyclic := map[string]any{"a": 1}
+	cyclic["self"] = cyclic
+
+	user := &normalizeUser{Name: "alice", secret: "s"}
+	user.Manager = user
+
+	tests := []struct {
+		name     string
+		cfg      Config
+		fields   map[string]any
+		expected map[string]any
+	}{
+		{"nil", Config{}, nil, nil},
+		{
+			"plain values",
+			Config{},
+			map[string]any{"int": 1, "float": 1.5, "bool": true, "string": "s", "nil": nil, "status": normalizeStatus(2)},
+			map[string]any{"int": 1, "float": 1.5, "bool": true, "string": "s", "nil": nil, "status": int64(2)},
+		},
+		{
+			"marshalers",
+			Config{},
+			map[string]any{
+				"error":    errors.New("err"),
+				"json":     time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+				"text":     net.IPv4(127, 0, 0, 1),
+				"stringer": time.Second,
+				"panic":    (*nilStringer)(nil),
+			},
+			map[string]any{
+				"error":    "err",
+				"json":     "2024-01-02T03:04:05Z",
+				"text":     "127.0.0.1",
+				"stringer": "1s",
+				"panic":    "!PANIC(runtime error: invalid memory address or nil pointer dereference)",
+			},
+		},
+		{
+			"bytes",
+			Config{},
+			map[string]any{"text": []byte("abc"), "binary": []byte{0xff, 0x00}},
+			map[string]any{"text": "abc", "binary": "/wA="},
+		},
+		{
+			"collections",
+			Config{},
+			map[string]any{"slice": []int{1, 2}, "map": map[int]string{1: "a"}, "func": func() {}},
+			map[string]any{"slice": []any{1, 2}, "map": map[string]any{"1": "a"}, "func": "<func()>"},
+		},
+		{
+			"cycles",
+			Config{},
+			map[string]any{"map": cyclic, "user": user},
+			map[string]any{
+				"map":          map[string]any{"a": 1, "self": "<cycle>"},
+				"user":         map[string]any{"Name": "alice", "Manager": "<cycle>"},
+				TruncatedField: true,
+			},
+		},
+		{
+			"max fields",
+			Config{MaxFields: 2},
+			map[string]any{"c": 3, "a": 1, "b": []int{1, 2, 3}},
+			map[string]any{"a": 1, "b": []any{1, 2}, TruncatedField: true},
+		},
+		{
+			"max bytes",
+			Config{MaxFieldBytes: 4},
+			map[string]any{"ascii": "abcdef", "unicode": "ыыы", "short": "abc"},
+			map[string]any{"ascii": "abcd...", "unicode": "ыы...", "short": "abc", TruncatedField: true},
+		},
+		{
+			"max depth",
+			Config{MaxFieldDepth: 1},
+			map[string]any{"flat": []int{1}, "nested": [][]int{{1}}},
+			map[string]any{"flat": []any{1}, "nested": []any{"<[]int>"}, TruncatedField: true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := normalizeFields(&tt.cfg, tt.fields)
+			if !reflect.DeepEqual(tt.expected, got) {
+				t.Errorf("unexpected fields:\nexpected %#v\ngot      %#v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestDescribeNormalizesFields(t *testing.T) {
+	configureForTest(t, Config{MaxFieldBytes: 8})
+
+	raw := strings.Repeat("x", 100)
+	err := WithField(errors.New("err"), "payload", []byte(raw))
+
+	d := Describe(err)
+	expected := map[string]any{"payload": "xxxxxxxx...", TruncatedField: true}
+	if !reflect.DeepEqual(expected, d.Fields) {
+		t.Errorf("unexpected fields: expected %v, got %v", expected, d.Fields)
+	}
+
+	if !reflect.DeepEqual([]byte(raw), Fields(err)["payload"]) {
+		t.Error("expect Fields to return the attached value")
+	}
+}
@@ -21,6 +21,15 @@ func newOpaque(msg string, err error) error {
 
 func (w *opaqueError) Error() string { return w.message }
 func (w *opaqueError) Unwrap() error { return w.cause }
+
+// Opaque reports that the error hides the message of its cause.
+func (w *opaqueError) Opaque() bool { return true }
+
 func (w *opaqueError) Format(f fmt.State, verb rune) {
-	fmt.Printf(fmt.FormatString(f, verb), w.cause)
+	if verb == 'v' && f.Flag('+') {
+		fmt.Fprintf(f, "%s\n%+v", w.message, w.cause)
+		return
+	}
+
+	fmt.Fprintf(f, fmt.FormatString(f, verb), w.message)
 }
@@ -0,0 +1,140 @@
+package cerrors
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"sync"
+	"time"
+)
+
+type Origin struct {
+	Time      time.Time `json:"time"`
+	Host      string    `json:"host"`
+	PID       int       `json:"pid"`
+	Goroutine uint64    `json:"goroutine"`
+	Revision  string    `json:"revision,omitempty"`
+}
+
+type withOrigin interface {
+	error
+	fmt.Formatter
+
+	Origin() Origin
+}
+
+// check interface implementation
+var _ withOrigin = (*withOriginError)(nil)
+
+type withOriginError struct {
+	cause  error
+	origin Origin
+}
+
+func enrichWithOrigin(ctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	cfg := configFrom(ctx)
+	if !cfg.CaptureOrigin {
+		return err
+	}
+
+	var oErr withOrigin
+	if errors.As(err, &oErr) {
+		return err
+	}
+
+	return &withOriginError{cause: err, origin: captureOrigin(cfg)}
+}
+
+func (w *withOriginError) Error() string  { return w.cause.Error() }
+func (w *withOriginError) Unwrap() error  { return w.cause }
+func (w *withOriginError) Origin() Origin { return w.origin }
+func (w *withOriginError) Format(s fmt.State, verb rune) {
+	if verb == 'v' && s.Flag('+') {
+		fmt.Fprintf(s, "%+v", w.cause)
+		w.origin.Format(s, verb)
+		return
+	}
+
+	fmt.Fprintf(s, fmt.FormatString(s, verb), w.cause)
+}
+
+// Format formats the origin according to the fmt.Formatter interface.
+//
+//	%+v   Prints every captured value on its own line.
+func (o Origin) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		if s.Flag('+') {
+			io.WriteString(s, "\ntime: "+o.Time.Format(time.RFC3339Nano))
+			io.WriteString(s, "\nhost: "+o.Host)
+			io.WriteString(s, "\npid: "+strconv.Itoa(o.PID))
+			io.WriteString(s, "\ngoroutine: "+strconv.FormatUint(o.Goroutine, 10))
+			if o.Revision != "" {
+				io.WriteString(s, "\nrevision: "+o.Revision)
+			}
+			return
+		}
+		fallthrough
+	case 's':
+		fmt.Fprintf(s, "%s %s[%d]", o.Time.Format(time.RFC3339Nano), o.Host, o.PID)
+	}
+}
+
+var (
+	processOnce     sync.Once
+	processHost     string
+	processRevision string
+)
+
+func captureOrigin(cfg *Config) Origin {
+	processOnce.Do(func() {
+		processHost, _ = os.Hostname()
+		processRevision = buildRevision()
+	})
+
+	return Origin{
+		Time:      cfg.now(),
+		Host:      processHost,
+		PID:       os.Getpid(),
+		Goroutine: goroutineID(),
+		Revision:  processRevision,
+	}
+}
+
+func buildRevision() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+
+	for _, setting := range info.Settings {
+		if setting.Key == "vcs.revision" {
+			return setting.Value
+		}
+	}
+
+	return ""
+}
+
+// goroutineID parses the id from the "goroutine 42 [running]:" header of runtime.Stack.
+func goroutineID() uint64 {
+	var buf [64]byte
+	b := buf[:runtime.Stack(buf[:], false)]
+	b = bytes.TrimPrefix(b, []byte("goroutine "))
+	if i := bytes.IndexByte(b, ' '); i >= 0 {
+		b = b[:i]
+	}
+
+	id, _ := strconv.ParseUint(string(b), 10, 64)
+	return id
+}
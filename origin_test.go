@@ -0,0 +1,113 @@
+package cerrors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOriginOf(t *testing.T) {
+	t.Run("nil", func(t *testing.T) {
+		if _, ok := OriginOf(nil); ok {
+			t.Error("expect no origin")
+		}
+	})
+
+	t.Run("disabled by default", func(t *testing.T) {
+		err := Enrich(context.Background(), errors.New("err"))
+
+		if _, ok := OriginOf(err); ok {
+			t.Error("expect no origin")
+		}
+		if !Time(err).IsZero() {
+			t.Error("expect zero time")
+		}
+	})
+
+	t.Run("captured", func(t *testing.T) {
+		configureForTest(t, Config{CaptureOrigin: true})
+
+		before := time.Now()
+		err := Enrich(context.Background(), errors.New("err"))
+
+		origin, ok := OriginOf(err)
+		if !ok {
+			t.Fatal("expect origin")
+		}
+
+		if origin.Time.Before(before) || !origin.Time.Equal(Time(err)) {
+			t.Errorf("unexpected time: %v", origin.Time)
+		}
+
+		host, _ := os.Hostname()
+		if origin.Host != host {
+			t.Errorf("unexpected host: expected %v, got %v", host, origin.Host)
+		}
+
+		if origin.PID != os.Getpid() {
+			t.Errorf("unexpected pid: expected %v, got %v", os.Getpid(), origin.PID)
+		}
+
+		if origin.Goroutine == 0 {
+			t.Error("expect goroutine id")
+		}
+	})
+
+	t.Run("nil context", func(t *testing.T) {
+		configureForTest(t, Config{CaptureOrigin: true})
+
+		err := Enrich(nil, errors.New("err"))
+
+		if _, ok := OriginOf(err); !ok {
+			t.Error("expect origin")
+		}
+	})
+
+	t.Run("kept on several calls", func(t *testing.T) {
+		configureForTest(t, Config{CaptureOrigin: true})
+
+		err := Enrich(context.Background(), errors.New("err"))
+		first := Time(err)
+		err = Enrich(context.Background(), err)
+
+		if !Time(err).Equal(first) {
+			t.Errorf("unexpected time: expected %v, got %v", first, Time(err))
+		}
+	})
+
+	t.Run("format", func(t *testing.T) {
+		configureForTest(t, Config{CaptureOrigin: true})
+
+		err := Enrich(context.Background(), errors.New("err"))
+
+		if fmt.Sprintf("%v", err) != "err" {
+			t.Errorf("unexpected message: %v", fmt.Sprintf("%v", err))
+		}
+
+		formatted := fmt.Sprintf("%+v", err)
+		expected := fmt.Sprintf("\npid: %d\n", os.Getpid())
+		if !strings.Contains(formatted, expected) {
+			t.Errorf("expect %q in %q", expected, formatted)
+		}
+	})
+}
+
+func TestGoroutineID(t *testing.T) {
+	ids := make(chan uint64)
+	go func() { ids <- goroutineID() }()
+
+	current, other := goroutineID(), <-ids
+	if current == 0 || other == 0 || current == other {
+		t.Errorf("unexpected goroutine ids: %d, %d", current, other)
+	}
+}
+
+func configureForTest(t *testing.T, cfg Config) {
+	previous := *currentConfig()
+	Configure(cfg)
+	t.Cleanup(func() { Configure(previous) })
+}
@@ -0,0 +1,252 @@
+package cerrors
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/sloory/cerrors/internal/stdlib"
+)
+
+type ColorMode int
+
+const (
+	// ColorAuto uses colors when the writer is a terminal and NO_COLOR is not set.
+	ColorAuto ColorMode = iota
+	ColorAlways
+	ColorNever
+)
+
+type PrettyOptions struct {
+	Color ColorMode
+	// AllFrames keeps frames of the standard library, which are dropped by default.
+	AllFrames bool
+	// SourceContext is the number of source lines shown before and after application frames.
+	SourceContext int
+}
+
+const (
+	ansiReset  = "\x1b[0m"
+	ansiBold   = "\x1b[1m"
+	ansiDim    = "\x1b[2m"
+	ansiRed    = "\x1b[31m"
+	ansiYellow = "\x1b[33m"
+	ansiCyan   = "\x1b[36m"
+)
+
+// Pretty writes a human-readable tree of err for CLIs and local development:
+// the public message with its causes, code and kind, the component path, fields,
+// breadcrumbs and the stack with application frames highlighted.
+func Pretty(w io.Writer, err error, opts PrettyOptions) error {
+	if err == nil {
+		return nil
+	}
+
+	p := &prettyPrinter{color: useColor(w, opts.Color)}
+	p.chain(err)
+
+	d := Describe(err)
+	if d.Code != "" || d.Kind != "" {
+		p.line("")
+		if d.Code != "" {
+			p.line("%s %s", p.paint(ansiDim, "code:"), d.Code)
+		}
+		if d.Kind != "" {
+			p.line("%s %s", p.paint(ansiDim, "kind:"), d.Kind)
+		}
+	}
+
+	if len(d.Components) > 0 {
+		p.line("")
+		p.line("%s %s", p.paint(ansiDim, "path:"), p.paint(ansiCyan, strings.Join(d.Components, " › ")))
+	}
+
+	if len(d.Fields) > 0 {
+		p.line("")
+		p.line("%s", p.paint(ansiDim, "fields:"))
+		p.fields(d.Fields)
+	}
+
+	if len(d.Breadcrumbs) > 0 {
+		p.line("")
+		p.line("%s", p.paint(ansiDim, "breadcrumbs:"))
+		p.breadcrumbs(d.Breadcrumbs)
+	}
+
+	if len(d.Stack) > 0 {
+		p.line("")
+		p.line("%s", p.paint(ansiDim, "stack:"))
+		p.stack(d.Stack, opts)
+	}
+	for _, stack := range d.Rethrown {
+		p.line("")
+		p.line("%s", p.paint(ansiDim, "rethrown at:"))
+		p.stack(stack, opts)
+	}
+
+	_, err = io.WriteString(w, p.b.String())
+	return err
+}
+
+type prettyPrinter struct {
+	b     strings.Builder
+	color bool
+}
+
+func (p *prettyPrinter) paint(code, s string) string {
+	if !p.color {
+		return s
+	}
+
+	return code + s + ansiReset
+}
+
+func (p *prettyPrinter) line(format string, args ...any) {
+	fmt.Fprintf(&p.b, format, args...)
+	p.b.WriteString("\n")
+}
+
+// chain prints the outermost message and causes with different messages,
+// marking the ones hidden behind Opaque as internal.
+func (p *prettyPrinter) chain(err error) {
+	p.line("%s", p.paint(ansiBold+ansiRed, "✗ "+err.Error()))
+
+	type cause struct {
+		msg      string
+		internal bool
+	}
+
+	var causes []cause
+	last, internal := err.Error(), false
+	for ; err != nil; err = errors.Unwrap(err) {
+		if msg := err.Error(); msg != last {
+			causes = append(causes, cause{msg: msg, internal: internal})
+			last = msg
+		}
+		if o, ok := err.(interface{ Opaque() bool }); ok && o.Opaque() {
+			internal = true
+		}
+	}
+
+	for i, c := range causes {
+		branch := "├─ "
+		if i == len(causes)-1 {
+			branch = "└─ "
+		}
+
+		msg := c.msg
+		if c.internal {
+			msg += " " + p.paint(ansiDim, "(internal)")
+		}
+		p.line("%s%s", p.paint(ansiDim, branch), msg)
+	}
+}
+
+func (p *prettyPrinter) fields(fields map[string]any) {
+	keys := make([]string, 0, len(fields))
+	width := 0
+	for k := range fields {
+		keys = append(keys, k)
+		if len(k) > width {
+			width = len(k)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		p.line("  %s = %s", p.paint(ansiCyan, fmt.Sprintf("%-*s", width, k)), prettyValue(fields[k]))
+	}
+}
+
+// breadcrumbs prints crumbs from the oldest one with their data sorted by key.
+func (p *prettyPrinter) breadcrumbs(crumbs []Crumb) {
+	for _, c := range crumbs {
+		keys := make([]string, 0, len(c.Data))
+		for k := range c.Data {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		var data strings.Builder
+		for _, k := range keys {
+			fmt.Fprintf(&data, " %s=%s", k, prettyValue(c.Data[k]))
+		}
+
+		p.line("  %s %s %s%s", p.paint(ansiDim, c.Time.Format("15:04:05.000")),
+			p.paint(ansiCyan, c.Category), c.Message, p.paint(ansiDim, data.String()))
+	}
+}
+
+// prettyValue quotes values which would break the layout, like logfmt does.
+func prettyValue(v any) string {
+	return string(appendLogfmtValue(nil, fmt.Sprint(v)))
+}
+
+func (p *prettyPrinter) stack(frames []FrameDetails, opts PrettyOptions) {
+	hidden := 0
+	for _, f := range frames {
+		if !opts.AllFrames && stdlib.Contains(f.Function) {
+			hidden++
+			continue
+		}
+
+		location := f.File + ":" + strconv.Itoa(f.Line)
+		if !isInApp(f.Function, f.File) {
+			p.line("  %s", p.paint(ansiDim, f.Function))
+			p.line("      %s", p.paint(ansiDim, location))
+			continue
+		}
+
+		p.line("  %s", p.paint(ansiBold+ansiYellow, f.Function))
+		p.line("      %s", location)
+		if opts.SourceContext > 0 {
+			p.source(f, opts.SourceContext)
+		}
+	}
+
+	if hidden > 0 {
+		p.line("  %s", p.paint(ansiDim, fmt.Sprintf("... %d standard library frames hidden", hidden)))
+	}
+}
+
+func (p *prettyPrinter) source(f FrameDetails, around int) {
+	snippet := sourceSnippet(f.File, f.Line, around)
+	if len(snippet) == 0 {
+		return
+	}
+
+	width := len(strconv.Itoa(snippet[len(snippet)-1].number))
+	for _, l := range snippet {
+		text := fmt.Sprintf("%*d | %s", width, l.number, l.text)
+		if l.current {
+			p.line("    > %s", p.paint(ansiBold, text))
+			continue
+		}
+		p.line("      %s", p.paint(ansiDim, text))
+	}
+}
+
+func useColor(w io.Writer, mode ColorMode) bool {
+	switch mode {
+	case ColorAlways:
+		return true
+	case ColorNever:
+		return false
+	}
+
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,204 @@
+package cerrors
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func prettyTestError() error {
+	ctx := InComponent(context.Background(), "api")
+	ctx = InComponent(ctx, "users")
+
+	err := Wrap("query user", errors.New("connection reset"))
+	err = Enrich(ctx, err)
+	err = WithFields(err, map[string]any{"userId": 42, "db": "main"})
+	err = WithKind(WithCode(err, "user.unavailable"), KindUnavailable)
+	return Opaque("user service is unavailable", err)
+}
+
+// foreignOpaque hides its cause like Opaque does.
+type foreignOpaque struct{ cause error }
+
+func (e *foreignOpaque) Error() string { return "internal error" }
+func (e *foreignOpaque) Unwrap() error { return e.cause }
+func (e *foreignOpaque) Opaque() bool  { return true }
+
+func TestPretty(t *testing.T) {
+	t.Run("nil", func(t *testing.T) {
+		var b bytes.Buffer
+		if err := Pretty(&b, nil, PrettyOptions{}); err != nil || b.Len() != 0 {
+			t.Errorf("unexpected output %q, error %v", b.String(), err)
+		}
+	})
+
+	t.Run("tree", func(t *testing.T) {
+		var b bytes.Buffer
+		if err := Pretty(&b, prettyTestError(), PrettyOptions{Color: ColorNever}); err != nil {
+			t.Fatal(err)
+		}
+		out := b.String()
+
+		expected := []string{
+			"✗ user service is unavailable\n" +
+				"├─ query user: connection reset (internal)\n" +
+				"└─ connection reset (internal)\n",
+			"code: user.unavailable\nkind: unavailable\n",
+			"path: api › users\n",
+			"fields:\n  db     = main\n  userId = 42\n",
+			"stack:\n  github.com/sloory/cerrors.prettyTestError\n",
+			"standard library frames hidden",
+		}
+		for _, s := range expected {
+			if !strings.Contains(out, s) {
+				t.Errorf("expect %q in output:\n%s", s, out)
+			}
+		}
+
+		if strings.Contains(out, "\x1b[") {
+			t.Errorf("unexpected colors:\n%s", out)
+		}
+		if strings.Contains(out, "testing.tRunner") {
+			t.Errorf("unexpected standard library frame:\n%s", out)
+		}
+	})
+
+	t.Run("all frames", func(t *testing.T) {
+		var b bytes.Buffer
+		if err := Pretty(&b, prettyTestError(), PrettyOptions{Color: ColorNever, AllFrames: true}); err != nil {
+			t.Fatal(err)
+		}
+
+		if !strings.Contains(b.String(), "testing.tRunner") {
+			t.Errorf("expect standard library frame:\n%s", b.String())
+		}
+	})
+
+	t.Run("source", func(t *testing.T) {
+		var b bytes.Buffer
+		if err := Pretty(&b, prettyTestError(), PrettyOptions{Color: ColorNever, SourceContext: 1}); err != nil {
+			t.Fatal(err)
+		}
+
+		if !strings.Contains(b.String(), `| 	err = Enrich(ctx, err)`) {
+			t.Errorf("expect source:\n%s", b.String())
+		}
+	})
+
+	t.Run("percent in message", func(t *testing.T) {
+		err := WithField(Wrap("disk 100% full", errors.New("write 5% of %d")), "usage", "100%")
+
+		var b bytes.Buffer
+		if err := Pretty(&b, err, PrettyOptions{Color: ColorNever}); err != nil {
+			t.Fatal(err)
+		}
+		out := b.String()
+
+		for _, s := range []string{"✗ disk 100% full: write 5% of %d\n", "└─ write 5% of %d\n", "usage = 100%\n"} {
+			if !strings.Contains(out, s) {
+				t.Errorf("expect %q in output:\n%s", s, out)
+			}
+		}
+		if strings.Contains(out, "%!") {
+			t.Errorf("unexpected formatting directive:\n%s", out)
+		}
+	})
+
+	t.Run("quoted field values", func(t *testing.T) {
+		err := WithFields(errors.New("err"), map[string]any{"query": "select 1\nfrom users", "name": "a b"})
+
+		var b bytes.Buffer
+		if err := Pretty(&b, err, PrettyOptions{Color: ColorNever}); err != nil {
+			t.Fatal(err)
+		}
+
+		expected := "fields:\n  name  = \"a b\"\n  query = \"select 1\\nfrom users\"\n"
+		if !strings.Contains(b.String(), expected) {
+			t.Errorf("expect %q in output:\n%s", expected, b.String())
+		}
+	})
+
+	t.Run("foreign opaque error", func(t *testing.T) {
+		err := &foreignOpaque{cause: Wrap("query user", errors.New("connection reset"))}
+
+		var b bytes.Buffer
+		if err := Pretty(&b, err, PrettyOptions{Color: ColorNever}); err != nil {
+			t.Fatal(err)
+		}
+
+		expected := "├─ query user: connection reset (internal)\n"
+		if !strings.Contains(b.String(), expected) {
+			t.Errorf("expect %q in output:\n%s", expected, b.String())
+		}
+	})
+
+	t.Run("breadcrumbs", func(t *testing.T) {
+		configureForTest(t, Config{Clock: func() time.Time {
+			return time.Date(2024, 5, 1, 12, 30, 15, 250e6, time.UTC)
+		}})
+
+		ctx := WithBreadcrumbs(context.Background(), 4)
+		Breadcrumb(ctx, "db", "query users", map[string]any{"table": "users", "rows": 0})
+		Breadcrumb(ctx, "http", "100% done", nil)
+
+		var b bytes.Buffer
+		if err := Pretty(&b, Enrich(ctx, errors.New("not found")), PrettyOptions{Color: ColorNever}); err != nil {
+			t.Fatal(err)
+		}
+
+		expected := "breadcrumbs:\n" +
+			"  12:30:15.250 db query users rows=0 table=users\n" +
+			"  12:30:15.250 http 100% done\n"
+		if !strings.Contains(b.String(), expected) {
+			t.Errorf("expect %q in output:\n%s", expected, b.String())
+		}
+	})
+
+	t.Run("colors", func(t *testing.T) {
+		var b bytes.Buffer
+		if err := Pretty(&b, prettyTestError(), PrettyOptions{Color: ColorAlways}); err != nil {
+			t.Fatal(err)
+		}
+
+		if !strings.Contains(b.String(), ansiBold+ansiYellow+"github.com/sloory/cerrors.prettyTestError"+ansiReset) {
+			t.Errorf("expect highlighted application frame:\n%q", b.String())
+		}
+	})
+}
+
+func TestUseColor(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+
+	tests := []struct {
+		name     string
+		w        io.Writer
+		mode     ColorMode
+		noColor  string
+		expected bool
+	}{
+		{"always", &bytes.Buffer{}, ColorAlways, "1", true},
+		{"never", w, ColorNever, "", false},
+		{"auto buffer", &bytes.Buffer{}, ColorAuto, "", false},
+		{"auto pipe", w, ColorAuto, "", false},
+		{"auto no color", os.Stdout, ColorAuto, "1", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("NO_COLOR", tt.noColor)
+
+			if got := useColor(tt.w, tt.mode); got != tt.expected {
+				t.Errorf("unexpected result: expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,203 @@
+package cerrors
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+const (
+	// BaggageHeader is the W3C baggage header components and fields are propagated in.
+	BaggageHeader = "baggage"
+
+	baggagePrefix          = "cerrors."
+	baggageComponentsKey   = baggagePrefix + "components"
+	baggageFieldPrefix     = baggagePrefix + "field."
+	defaultBaggageMaxBytes = 8192
+	// maxBaggageMembers is the W3C limit of members in a baggage.
+	maxBaggageMembers = 64
+)
+
+// Carrier gets and sets headers of a request, see HeaderCarrier and MetadataCarrier.
+type Carrier interface {
+	Get(key string) string
+	Set(key, value string)
+}
+
+// HeaderCarrier adapts http.Header to Carrier.
+type HeaderCarrier http.Header
+
+func (c HeaderCarrier) Get(key string) string { return strings.Join(http.Header(c).Values(key), ",") }
+func (c HeaderCarrier) Set(key, value string) { http.Header(c).Set(key, value) }
+
+// MetadataCarrier adapts gRPC metadata.MD to Carrier without depending on gRPC:
+//
+//	cerrors.MetadataCarrier(md)
+type MetadataCarrier map[string][]string
+
+func (c MetadataCarrier) Get(key string) string { return strings.Join(c[strings.ToLower(key)], ",") }
+func (c MetadataCarrier) Set(key, value string) { c[strings.ToLower(key)] = []string{value} }
+
+// Propagator passes the component path and allowed ctx fields of a caller to the
+// services it calls, so errors enriched there show the full distributed path.
+// They are kept in W3C baggage members prefixed with "cerrors.", next to members
+// of other libraries.
+type Propagator struct {
+	// Fields lists ctx fields which are passed, both when injecting and extracting.
+	// No fields are passed by default.
+	Fields []string
+	// MaxBytes limits the size of cerrors members, 8192 bytes by default.
+	// Members which do not fit are dropped, fields are added after the components
+	// in key order.
+	MaxBytes int
+}
+
+// Inject adds components and allowed fields of ctx to the baggage of carrier.
+// Field values are passed as strings.
+func (p Propagator) Inject(ctx context.Context, carrier Carrier) {
+	if ctx == nil {
+		return
+	}
+
+	members := foreignBaggageMembers(carrier.Get(BaggageHeader))
+	size := 0
+	add := func(member string) {
+		if size+len(member) > p.maxBytes() || len(members) >= maxBaggageMembers {
+			return
+		}
+		size += len(member)
+		members = append(members, member)
+	}
+
+	if components := getCtxComponents(ctx); len(components) > 0 {
+		escaped := make([]string, 0, len(components))
+		for _, c := range components {
+			escaped = append(escaped, url.PathEscape(c))
+		}
+		add(baggageComponentsKey + "=" + strings.Join(escaped, "/"))
+	}
+
+	fields := CtxFields(ctx)
+	for _, k := range p.allowedFields() {
+		if v, ok := fields[k]; ok {
+			add(baggageFieldPrefix + k + "=" + url.PathEscape(fmt.Sprint(v)))
+		}
+	}
+
+	if len(members) > 0 {
+		carrier.Set(BaggageHeader, strings.Join(members, ","))
+	}
+}
+
+// Extract returns ctx with the components of the caller before its own ones
+// and with allowed fields from the baggage of carrier.
+func (p Propagator) Extract(ctx context.Context, carrier Carrier) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	allowed := make(map[string]bool, len(p.Fields))
+	for _, k := range p.allowedFields() {
+		allowed[k] = true
+	}
+
+	var components []string
+	fields := map[string]any{}
+	size := 0
+	for _, member := range strings.Split(carrier.Get(BaggageHeader), ",") {
+		member = strings.TrimSpace(member)
+		if i := strings.IndexByte(member, ';'); i >= 0 {
+			member = member[:i]
+		}
+
+		key, value, ok := strings.Cut(member, "=")
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		if !ok || !strings.HasPrefix(key, baggagePrefix) {
+			continue
+		}
+
+		size += len(member)
+		if size > p.maxBytes() {
+			break
+		}
+
+		switch {
+		case key == baggageComponentsKey:
+			components = components[:0]
+			for _, c := range strings.Split(value, "/") {
+				if c, err := url.PathUnescape(c); err == nil && c != "" {
+					components = append(components, c)
+				}
+			}
+		case strings.HasPrefix(key, baggageFieldPrefix) && allowed[strings.TrimPrefix(key, baggageFieldPrefix)]:
+			if v, err := url.PathUnescape(value); err == nil {
+				fields[strings.TrimPrefix(key, baggageFieldPrefix)] = v
+			}
+		}
+	}
+
+	if len(components) > 0 {
+		ctx = context.WithValue(ctx, componentsKey, append(components, getCtxComponents(ctx)...))
+	}
+
+	if len(fields) > 0 {
+		ctx = withFieldsCopy(ctx)
+		for k, v := range fields {
+			ctx = WithCtxField(ctx, k, v)
+		}
+	}
+
+	return ctx
+}
+
+func (p Propagator) maxBytes() int {
+	if p.MaxBytes <= 0 {
+		return defaultBaggageMaxBytes
+	}
+
+	return p.MaxBytes
+}
+
+// allowedFields returns allowed field names which are valid baggage keys, sorted.
+func (p Propagator) allowedFields() []string {
+	fields := make([]string, 0, len(p.Fields))
+	for _, k := range p.Fields {
+		if isBaggageToken(k) {
+			fields = append(fields, k)
+		}
+	}
+	sort.Strings(fields)
+
+	return fields
+}
+
+// foreignBaggageMembers returns members of baggage which do not belong to cerrors.
+func foreignBaggageMembers(baggage string) []string {
+	var members []string
+	for _, member := range strings.Split(baggage, ",") {
+		member = strings.TrimSpace(member)
+		if member == "" || strings.HasPrefix(member, baggagePrefix) {
+			continue
+		}
+		members = append(members, member)
+	}
+
+	return members
+}
+
+// isBaggageToken reports whether s is a token as defined by RFC 7230, which baggage keys are.
+func isBaggageToken(s string) bool {
+	if s == "" {
+		return false
+	}
+
+	for _, r := range s {
+		if r > '~' || r <= ' ' || strings.ContainsRune(`"(),/:;<=>?@[\]{}`, r) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,134 @@
+package cerrors
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPropagatorInject(t *testing.T) {
+	p := Propagator{Fields: []string{"requestId", "tenant", "bad key"}}
+
+	t.Run("components and allowed fields", func(t *testing.T) {
+		ctx := InComponent(InComponent(context.Background(), "api gateway"), "users/v2")
+		ctx = WithCtxField(ctx, "requestId", 42)
+		ctx = WithCtxField(ctx, "tenant", "a,b;c")
+		ctx = WithCtxField(ctx, "password", "secret")
+		ctx = WithCtxField(ctx, "bad key", "v")
+
+		h := http.Header{}
+		h.Set(BaggageHeader, "userId=alice, cerrors.components=stale;prop=1")
+		p.Inject(ctx, HeaderCarrier(h))
+
+		expected := "userId=alice,cerrors.components=api%20gateway/users%2Fv2," +
+			"cerrors.field.requestId=42,cerrors.field.tenant=a%2Cb%3Bc"
+		if got := h.Get(BaggageHeader); got != expected {
+			t.Errorf("unexpected baggage:\nexpected %s\ngot      %s", expected, got)
+		}
+	})
+
+	t.Run("empty context", func(t *testing.T) {
+		h := http.Header{}
+		p.Inject(context.Background(), HeaderCarrier(h))
+
+		if _, ok := h[BaggageHeader]; ok {
+			t.Errorf("unexpected baggage: %v", h)
+		}
+	})
+
+	t.Run("size limit", func(t *testing.T) {
+		ctx := InComponent(context.Background(), "api")
+		ctx = WithCtxField(ctx, "requestId", strings.Repeat("x", 100))
+		ctx = WithCtxField(ctx, "tenant", "t")
+
+		md := MetadataCarrier{}
+		Propagator{Fields: p.Fields, MaxBytes: 60}.Inject(ctx, md)
+
+		expected := []string{"cerrors.components=api,cerrors.field.tenant=t"}
+		if !reflect.DeepEqual(expected, md[BaggageHeader]) {
+			t.Errorf("unexpected baggage: expected %v, got %v", expected, md[BaggageHeader])
+		}
+	})
+}
+
+func TestPropagatorExtract(t *testing.T) {
+	p := Propagator{Fields: []string{"requestId"}}
+
+	t.Run("components and allowed fields", func(t *testing.T) {
+		base := WithCtxField(context.Background(), "server", "b1")
+		h := http.Header{}
+		h.Add(BaggageHeader, "userId=alice,cerrors.components=api%20gateway/users%2Fv2")
+		h.Add(BaggageHeader, "cerrors.field.requestId=42;prop=1, cerrors.field.password=secret")
+
+		ctx := InComponent(p.Extract(base, HeaderCarrier(h)), "billing")
+
+		expected := []string{"api gateway", "users/v2", "billing"}
+		if !reflect.DeepEqual(expected, getCtxComponents(ctx)) {
+			t.Errorf("unexpected components: expected %v, got %v", expected, getCtxComponents(ctx))
+		}
+
+		expectedFields := map[string]any{"server": "b1", "requestId": "42"}
+		if !reflect.DeepEqual(expectedFields, CtxFields(ctx)) {
+			t.Errorf("unexpected fields: expected %v, got %v", expectedFields, CtxFields(ctx))
+		}
+
+		if _, ok := CtxFields(base)["requestId"]; ok {
+			t.Error("field leaked to base context")
+		}
+	})
+
+	t.Run("size limit", func(t *testing.T) {
+		md := MetadataCarrier{"baggage": {"cerrors.components=api,cerrors.field.requestId=" + strings.Repeat("x", 100)}}
+
+		ctx := Propagator{Fields: p.Fields, MaxBytes: 60}.Extract(context.Background(), md)
+
+		if !reflect.DeepEqual([]string{"api"}, getCtxComponents(ctx)) || CtxFields(ctx) != nil {
+			t.Errorf("unexpected context: components %v, fields %v", getCtxComponents(ctx), CtxFields(ctx))
+		}
+	})
+
+	t.Run("invalid members", func(t *testing.T) {
+		md := MetadataCarrier{"baggage": {"cerrors.components, cerrors.components=%zz/ok,,="}}
+
+		ctx := p.Extract(context.Background(), md)
+
+		if !reflect.DeepEqual([]string{"ok"}, getCtxComponents(ctx)) {
+			t.Errorf("unexpected components: %v", getCtxComponents(ctx))
+		}
+	})
+}
+
+func TestPropagatorHTTP(t *testing.T) {
+	p := Propagator{Fields: []string{"requestId"}}
+
+	var callee error
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := InComponent(p.Extract(r.Context(), HeaderCarrier(r.Header)), "billing")
+		callee = Enrich(ctx, errors.New("charge failed"))
+	}))
+	defer srv.Close()
+
+	ctx := InComponent(context.Background(), "checkout")
+	ctx = WithCtxField(ctx, "requestId", "r-1")
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.Inject(ctx, HeaderCarrier(req.Header))
+
+	resp, err := srv.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if expected := []string{"checkout", "billing"}; !reflect.DeepEqual(expected, Components(callee)) {
+		t.Errorf("unexpected components: expected %v, got %v", expected, Components(callee))
+	}
+	requireFields(t, callee, map[string]any{"requestId": "r-1"})
+}
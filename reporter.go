@@ -0,0 +1,8 @@
+package cerrors
+
+import "context"
+
+// Reporter sends enriched errors to logs or an error tracker.
+type Reporter interface {
+	Report(ctx context.Context, err error)
+}
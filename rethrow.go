@@ -0,0 +1,53 @@
+package cerrors
+
+import (
+	"errors"
+	"strings"
+)
+
+func rethrowGoroutine(cfg *Config) uint64 {
+	if !cfg.RethrowStacks {
+		return 0
+	}
+
+	return goroutineID()
+}
+
+// isRethrown reports whether stack, captured on goroutine, is not where the latest
+// stack of err was captured: the goroutine differs when both are known, otherwise
+// the outermost function of the stacks differs.
+func isRethrown(err error, stack StackTrace, goroutine uint64) bool {
+	var last *withStack
+	if errors.As(err, &last) && last.goroutine != 0 && goroutine != 0 {
+		return last.goroutine != goroutine
+	}
+
+	if all := stacks(err); len(all) > 0 {
+		return stackRoot(all[0]) != stackRoot(stack)
+	}
+
+	return false
+}
+
+// stackRoot returns the outermost function of stack which is not a part of the runtime.
+func stackRoot(stack StackTrace) string {
+	for i := len(stack) - 1; i >= 0; i-- {
+		if name := stack[i].name(); !strings.HasPrefix(name, "runtime.") {
+			return name
+		}
+	}
+
+	return ""
+}
+
+// Stacks returns all stacks of the tree of err, including errors joined with errors.Join,
+// from the deepest one, where the error happened, to the outermost one, where it was
+// rethrown last.
+func Stacks(err error) []StackTrace {
+	res := stacks(err)
+	for i, j := 0, len(res)-1; i < j; i, j = i+1, j-1 {
+		res[i], res[j] = res[j], res[i]
+	}
+
+	return res
+}
@@ -0,0 +1,94 @@
+package cerrors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestRethrowStacks(t *testing.T) {
+	fromGoroutine := func(ctx context.Context) error {
+		errs := make(chan error)
+		go func() {
+			errs <- Enrich(ctx, errors.New("err"))
+		}()
+		return <-errs
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		ctx := context.Background()
+
+		err := Enrich(ctx, fromGoroutine(ctx))
+
+		if len(Stacks(err)) != 1 {
+			t.Errorf("unexpected stacks count: expected %d, got %d", 1, len(Stacks(err)))
+		}
+	})
+
+	t.Run("goroutine hop", func(t *testing.T) {
+		ctx := WithConfig(context.Background(), Config{RethrowStacks: true})
+
+		err := Enrich(ctx, fromGoroutine(ctx))
+		err = Enrich(ctx, err)
+
+		stacks := Stacks(err)
+		if len(stacks) != 2 {
+			t.Fatalf("unexpected stacks count: expected %d, got %d", 2, len(stacks))
+		}
+
+		if !strings.HasSuffix(stacks[0][0].Function(), "TestRethrowStacks.func1.1") {
+			t.Errorf("unexpected first stack: %v", stacks[0][0].Function())
+		}
+
+		if !strings.HasSuffix(stacks[1][0].Function(), "TestRethrowStacks.func3") {
+			t.Errorf("unexpected rethrown stack: %v", stacks[1][0].Function())
+		}
+
+		if !strings.HasSuffix(Stack(err)[0].Function(), "TestRethrowStacks.func1.1") {
+			t.Errorf("unexpected deepest stack: %v", Stack(err)[0].Function())
+		}
+
+		d := Describe(err)
+		if len(d.Rethrown) != 1 || d.Rethrown[0][0].Function != stacks[1][0].Function() {
+			t.Errorf("unexpected rethrown details: %+v", d.Rethrown)
+		}
+	})
+
+	t.Run("same goroutine", func(t *testing.T) {
+		ctx := WithConfig(context.Background(), Config{RethrowStacks: true})
+
+		err := Enrich(ctx, errors.New("err"))
+		err = Enrich(ctx, Wrap("wrapped", err))
+
+		if len(Stacks(err)) != 1 {
+			t.Errorf("unexpected stacks count: expected %d, got %d", 1, len(Stacks(err)))
+		}
+	})
+
+	t.Run("different stack root", func(t *testing.T) {
+		configureForTest(t, Config{RethrowStacks: true})
+
+		// the stack is captured without goroutine id
+		err := fromGoroutine(WithConfig(context.Background(), Config{}))
+		err = WithStack(err)
+
+		if len(Stacks(err)) != 2 {
+			t.Errorf("unexpected stacks count: expected %d, got %d", 2, len(Stacks(err)))
+		}
+	})
+
+	t.Run("format", func(t *testing.T) {
+		ctx := WithConfig(context.Background(), Config{RethrowStacks: true})
+
+		err := Enrich(ctx, fromGoroutine(ctx))
+
+		formatted := fmt.Sprintf("%+v", err)
+		first := strings.Index(formatted, "TestRethrowStacks.func1.1\n")
+		rethrown := strings.Index(formatted, "\nrethrown at:\ngithub.com/sloory/cerrors.TestRethrowStacks.func6\n")
+		if first < 0 || rethrown < first {
+			t.Errorf("unexpected format:\n%s", formatted)
+		}
+	})
+}
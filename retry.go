@@ -0,0 +1,51 @@
+package cerrors
+
+import (
+	"errors"
+	"fmt"
+)
+
+type withRetryable interface {
+	error
+	fmt.Formatter
+
+	Retryable() bool
+}
+
+// check interface implementation
+var _ withRetryable = (*withRetryableError)(nil)
+
+type withRetryableError struct {
+	cause     error
+	retryable bool
+}
+
+func (w *withRetryableError) Error() string   { return w.cause.Error() }
+func (w *withRetryableError) Unwrap() error   { return w.cause }
+func (w *withRetryableError) Retryable() bool { return w.retryable }
+func (w *withRetryableError) Format(f fmt.State, verb rune) {
+	fmt.Fprintf(f, fmt.FormatString(f, verb), w.cause)
+}
+
+// WithRetryable marks whether the operation which failed with err may succeed when retried.
+func WithRetryable(err error, retryable bool) error {
+	if err == nil {
+		return nil
+	}
+
+	return &withRetryableError{cause: err, retryable: retryable}
+}
+
+// IsRetryable returns the outermost retryable mark in the chain of err, false when there is none.
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var rErr withRetryable
+	if errors.As(err, &rErr) {
+		return rErr.Retryable()
+	}
+
+	return false
+}
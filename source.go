@@ -0,0 +1,108 @@
+package cerrors
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/sloory/cerrors/internal/stdlib"
+)
+
+// maxSourceFiles bounds the number of files kept in sourceCache.
+const maxSourceFiles = 64
+
+// sourceCache keeps lines of source files read for snippets, nil for files which
+// cannot be read. The file read first is evicted when the cache is full.
+var sourceCache = struct {
+	sync.Mutex
+	files map[string][]string
+	order []string
+}{files: make(map[string][]string)}
+
+func sourceFile(file string) []string {
+	sourceCache.Lock()
+	lines, ok := sourceCache.files[file]
+	sourceCache.Unlock()
+	if ok {
+		return lines
+	}
+
+	if data, err := os.ReadFile(file); err == nil {
+		lines = strings.Split(string(bytes.TrimRight(data, "\n")), "\n")
+	}
+
+	sourceCache.Lock()
+	defer sourceCache.Unlock()
+
+	if cached, ok := sourceCache.files[file]; ok {
+		return cached
+	}
+
+	if len(sourceCache.order) >= maxSourceFiles {
+		delete(sourceCache.files, sourceCache.order[0])
+		sourceCache.order = sourceCache.order[1:]
+	}
+	sourceCache.files[file] = lines
+	sourceCache.order = append(sourceCache.order, file)
+
+	return lines
+}
+
+type sourceLine struct {
+	number  int
+	text    string
+	current bool
+}
+
+// sourceSnippet returns up to around lines of source before and after line of file.
+func sourceSnippet(file string, line, around int) []sourceLine {
+	lines := sourceFile(file)
+	if line <= 0 || line > len(lines) {
+		return nil
+	}
+
+	from, to := line-around, line+around
+	if from < 1 {
+		from = 1
+	}
+	if to > len(lines) {
+		to = len(lines)
+	}
+
+	snippet := make([]sourceLine, 0, to-from+1)
+	for n := from; n <= to; n++ {
+		snippet = append(snippet, sourceLine{number: n, text: lines[n-1], current: n == line})
+	}
+	return snippet
+}
+
+// isInApp reports whether function, defined in file, belongs to the application,
+// and not to the standard library or a module from the module cache.
+func isInApp(function, file string) bool {
+	return !stdlib.Contains(function) && !strings.Contains(file, "/pkg/mod/")
+}
+
+func writeSourceSnippet(w io.Writer, f Frame, around int) {
+	file := f.file()
+	if !isInApp(f.name(), file) {
+		return
+	}
+
+	snippet := sourceSnippet(file, f.line(), around)
+	if len(snippet) == 0 {
+		return
+	}
+
+	width := len(strconv.Itoa(snippet[len(snippet)-1].number))
+	for _, l := range snippet {
+		marker := " "
+		if l.current {
+			marker = ">"
+		}
+		fmt.Fprintf(w, "\n\t%s %*d | %s", marker, width, l.number, l.text)
+	}
+}
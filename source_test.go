@@ -0,0 +1,131 @@
+package cerrors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func writeSourceFixture(t *testing.T, content string) string {
+	t.Helper()
+
+	file := filepath.Join(t.TempDir(), "fixture.go")
+	if err := os.WriteFile(file, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	return file
+}
+
+func TestSourceSnippet(t *testing.T) {
+	file := writeSourceFixture(t, "line1\nline2\nline3\nline4\nline5\n")
+
+	tests := []struct {
+		line, around int
+		expected     []sourceLine
+	}{
+		{3, 1, []sourceLine{{2, "line2", false}, {3, "line3", true}, {4, "line4", false}}},
+		{1, 2, []sourceLine{{1, "line1", true}, {2, "line2", false}, {3, "line3", false}}},
+		{5, 1, []sourceLine{{4, "line4", false}, {5, "line5", true}}},
+		{6, 1, nil},
+		{0, 1, nil},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("line %d around %d", tt.line, tt.around), func(t *testing.T) {
+			snippet := sourceSnippet(file, tt.line, tt.around)
+			if !reflect.DeepEqual(tt.expected, snippet) {
+				t.Errorf("unexpected snippet: expected %v, got %v", tt.expected, snippet)
+			}
+		})
+	}
+
+	t.Run("missing file", func(t *testing.T) {
+		if snippet := sourceSnippet(filepath.Join(t.TempDir(), "missing.go"), 1, 1); snippet != nil {
+			t.Errorf("unexpected snippet: %v", snippet)
+		}
+	})
+
+	t.Run("cached", func(t *testing.T) {
+		file := writeSourceFixture(t, "before\n")
+		sourceSnippet(file, 1, 0)
+
+		if err := os.WriteFile(file, []byte("after\n"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+
+		snippet := sourceSnippet(file, 1, 0)
+		if len(snippet) != 1 || snippet[0].text != "before" {
+			t.Errorf("expect cached source, got %v", snippet)
+		}
+	})
+
+	t.Run("cache bounded", func(t *testing.T) {
+		dir := t.TempDir()
+		for i := 0; i < maxSourceFiles+10; i++ {
+			sourceSnippet(filepath.Join(dir, fmt.Sprintf("missing%d.go", i)), 1, 0)
+		}
+
+		sourceCache.Lock()
+		defer sourceCache.Unlock()
+
+		if len(sourceCache.files) > maxSourceFiles || len(sourceCache.order) > maxSourceFiles {
+			t.Errorf("unexpected cached files count: %d", len(sourceCache.files))
+		}
+	})
+}
+
+func TestIsInApp(t *testing.T) {
+	tests := []struct {
+		function string
+		file     string
+		expected bool
+	}{
+		{"runtime.main", filepath.Join(runtime.GOROOT(), "src/runtime/proc.go"), false},
+		{"runtime.main", "runtime/proc.go", false},
+		{"github.com/pkg/errors.New", "/home/user/go/pkg/mod/github.com/pkg/errors@v0.9.1/errors.go", false},
+		{"main.main", "/home/user/app/main.go", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			if got := isInApp(tt.function, tt.file); got != tt.expected {
+				t.Errorf("unexpected result: expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestFormatSourceContext(t *testing.T) {
+	if out := fmt.Sprintf("%+v", WithStack(errors.New("err"))); strings.Contains(out, " | ") {
+		t.Errorf("unexpected source without SourceContext:\n%s", out)
+	}
+
+	t.Run("context", func(t *testing.T) {
+		ctx := WithConfig(context.Background(), Config{SourceContext: 1})
+
+		out := fmt.Sprintf("%+v", Enrich(ctx, errors.New("err")))
+		if !strings.Contains(out, `| 		out := fmt.Sprintf("%+v", Enrich(ctx, errors.New("err")))`) {
+			t.Errorf("expect source of the current line:\n%s", out)
+		}
+	})
+
+	configureForTest(t, Config{SourceContext: 1})
+
+	err := WithStack(errors.New("err"))
+	out := fmt.Sprintf("%+v", err)
+	if !strings.Contains(out, "> ") || !strings.Contains(out, `| 	err := WithStack(errors.New("err"))`) {
+		t.Errorf("expect source of the current line:\n%s", out)
+	}
+
+	lines := strings.Split(out, "\n")
+	for i, line := range lines {
+		if strings.HasPrefix(line, "testing.tRunner") && i+2 < len(lines) && strings.Contains(lines[i+2], " | ") {
+			t.Errorf("unexpected source of the standard library:\n%s", out)
+		}
+	}
+}
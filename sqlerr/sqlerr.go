@@ -0,0 +1,260 @@
+// Package sqlerr classifies errors of database/sql and its drivers into kinds and
+// retryable errors, attaching the constraint and the table of the failed statement as fields.
+//
+// Driver errors are recognized without importing drivers, by the shape of their
+// error types: Postgres errors by their SQLState method (*pgconn.PgError of pgx,
+// *pq.Error of lib/pq) with the constraint and the table read from their fields,
+// and MySQL errors by the Number uint16 and SQLState [5]byte fields of
+// *mysql.MySQLError of github.com/go-sql-driver/mysql.
+//
+// Classify can be called where driver errors are returned, or Hook can run in every Enrich:
+//
+//	cerrors.Configure(cerrors.Config{Hooks: []cerrors.EnrichHook{sqlerr.Hook}})
+package sqlerr
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"reflect"
+	"strings"
+
+	"github.com/sloory/cerrors"
+)
+
+type sqlStateError interface {
+	error
+	SQLState() string
+}
+
+type class struct {
+	kind      cerrors.Kind
+	retryable bool
+	fields    map[string]any
+}
+
+// Classify returns err with the kind, the retryable mark and fields of the database error
+// in its chain. Errors which already have a kind or are not database errors are returned as is.
+func Classify(err error) error {
+	if err == nil || cerrors.KindOf(err) != cerrors.KindUnknown {
+		return err
+	}
+
+	c, ok := classify(err)
+	if !ok {
+		return err
+	}
+
+	if len(c.fields) > 0 {
+		err = cerrors.WithFields(err, c.fields)
+	}
+	if c.retryable {
+		err = cerrors.WithRetryable(err, true)
+	}
+	return cerrors.WithKind(err, c.kind)
+}
+
+// Hook classifies errors inside Enrich, see Classify.
+func Hook(_ context.Context, e *cerrors.Enrichment) {
+	if cerrors.KindOf(e.Err) != cerrors.KindUnknown {
+		return
+	}
+
+	c, ok := classify(e.Err)
+	if !ok {
+		return
+	}
+
+	for k, v := range c.fields {
+		e.Fields[k] = v
+	}
+	if c.retryable {
+		e.Err = cerrors.WithRetryable(e.Err, true)
+	}
+	e.Err = cerrors.WithKind(e.Err, c.kind)
+}
+
+func classify(err error) (class, bool) {
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return class{kind: cerrors.KindNotFound}, true
+	case errors.Is(err, driver.ErrBadConn):
+		return class{kind: cerrors.KindUnavailable, retryable: true}, true
+	case errors.Is(err, sql.ErrConnDone):
+		return class{kind: cerrors.KindUnavailable}, true
+	case errors.Is(err, sql.ErrTxDone):
+		return class{kind: cerrors.KindInternal}, true
+	}
+
+	if number, state, ok := findMySQL(err); ok {
+		return classifyMySQL(number, state)
+	}
+
+	var sErr sqlStateError
+	if errors.As(err, &sErr) {
+		return classifyPostgres(sErr)
+	}
+
+	return class{}, false
+}
+
+// postgresCodes maps SQLSTATE codes to classes, see https://www.postgresql.org/docs/current/errcodes-appendix.html.
+var postgresCodes = map[string]class{
+	"23502": {kind: cerrors.KindInvalid},                      // not_null_violation
+	"23503": {kind: cerrors.KindInvalid},                      // foreign_key_violation
+	"23505": {kind: cerrors.KindConflict},                     // unique_violation
+	"23514": {kind: cerrors.KindInvalid},                      // check_violation
+	"23P01": {kind: cerrors.KindConflict},                     // exclusion_violation
+	"40001": {kind: cerrors.KindConflict, retryable: true},    // serialization_failure
+	"40P01": {kind: cerrors.KindConflict, retryable: true},    // deadlock_detected
+	"42501": {kind: cerrors.KindPermission},                   // insufficient_privilege
+	"55P03": {kind: cerrors.KindConflict, retryable: true},    // lock_not_available
+	"57014": {kind: cerrors.KindTimeout},                      // query_canceled
+	"57P01": {kind: cerrors.KindUnavailable, retryable: true}, // admin_shutdown
+	"57P02": {kind: cerrors.KindUnavailable, retryable: true}, // crash_shutdown
+	"57P03": {kind: cerrors.KindUnavailable, retryable: true}, // cannot_connect_now
+}
+
+// postgresClasses maps SQLSTATE classes, the first two characters of codes, to classes.
+var postgresClasses = map[string]class{
+	"08": {kind: cerrors.KindUnavailable, retryable: true}, // connection_exception
+	"22": {kind: cerrors.KindInvalid},                      // data_exception
+	"23": {kind: cerrors.KindConflict},                     // integrity_constraint_violation
+	"28": {kind: cerrors.KindUnauthenticated},              // invalid_authorization_specification
+	"40": {kind: cerrors.KindConflict, retryable: true},    // transaction_rollback
+	"42": {kind: cerrors.KindInternal},                     // syntax_error_or_access_rule_violation
+	"53": {kind: cerrors.KindUnavailable, retryable: true}, // insufficient_resources
+}
+
+func classifyPostgres(err sqlStateError) (class, bool) {
+	code := err.SQLState()
+
+	c, ok := postgresCodes[code]
+	if !ok && len(code) == 5 {
+		c, ok = postgresClasses[code[:2]]
+	}
+	if !ok {
+		c = class{kind: cerrors.KindInternal}
+	}
+
+	c.fields = map[string]any{"sqlstate": code}
+	// *pgconn.PgError and *pq.Error name these fields differently
+	for name, fields := range map[string][]string{
+		"constraint": {"ConstraintName", "Constraint"},
+		"table":      {"TableName", "Table"},
+		"schema":     {"SchemaName", "Schema"},
+		"column":     {"ColumnName", "Column"},
+	} {
+		if v := stringField(err, fields...); v != "" {
+			c.fields[name] = v
+		}
+	}
+
+	return c, true
+}
+
+// mysqlCodes maps MySQL server error numbers to classes,
+// see https://dev.mysql.com/doc/mysql-errors/8.0/en/server-error-reference.html.
+var mysqlCodes = map[uint16]class{
+	1040: {kind: cerrors.KindUnavailable, retryable: true}, // ER_CON_COUNT_ERROR
+	1044: {kind: cerrors.KindPermission},                   // ER_DBACCESS_DENIED_ERROR
+	1045: {kind: cerrors.KindUnauthenticated},              // ER_ACCESS_DENIED_ERROR
+	1048: {kind: cerrors.KindInvalid},                      // ER_BAD_NULL_ERROR
+	1062: {kind: cerrors.KindConflict},                     // ER_DUP_ENTRY
+	1142: {kind: cerrors.KindPermission},                   // ER_TABLEACCESS_DENIED_ERROR
+	1146: {kind: cerrors.KindInternal},                     // ER_NO_SUCH_TABLE
+	1205: {kind: cerrors.KindConflict, retryable: true},    // ER_LOCK_WAIT_TIMEOUT
+	1213: {kind: cerrors.KindConflict, retryable: true},    // ER_LOCK_DEADLOCK
+	1451: {kind: cerrors.KindInvalid},                      // ER_ROW_IS_REFERENCED_2
+	1452: {kind: cerrors.KindInvalid},                      // ER_NO_REFERENCED_ROW_2
+	1586: {kind: cerrors.KindConflict},                     // ER_DUP_ENTRY_WITH_KEY_NAME
+	3024: {kind: cerrors.KindTimeout},                      // ER_QUERY_TIMEOUT
+}
+
+func classifyMySQL(number uint16, state string) (class, bool) {
+	c, ok := mysqlCodes[number]
+	if !ok {
+		c = class{kind: cerrors.KindInternal}
+	}
+
+	c.fields = map[string]any{"mysql.number": number}
+	if state != "" {
+		c.fields["sqlstate"] = state
+	}
+
+	return c, true
+}
+
+// findMySQL returns the number and the SQLSTATE of the first error in the tree of err
+// shaped like *mysql.MySQLError, following both Unwrap() error and Unwrap() []error.
+func findMySQL(err error) (uint16, string, bool) {
+	for err != nil {
+		if number, state, ok := mysqlFields(err); ok {
+			return number, state, true
+		}
+
+		switch e := err.(type) {
+		case interface{ Unwrap() error }:
+			err = e.Unwrap()
+		case interface{ Unwrap() []error }:
+			for _, err := range e.Unwrap() {
+				if number, state, ok := findMySQL(err); ok {
+					return number, state, true
+				}
+			}
+			return 0, "", false
+		default:
+			return 0, "", false
+		}
+	}
+
+	return 0, "", false
+}
+
+// mysqlFields reads the Number uint16 and SQLState [5]byte fields of the struct err
+// points to, which are the fields of *mysql.MySQLError.
+func mysqlFields(err error) (uint16, string, bool) {
+	v, ok := structOf(err)
+	if !ok {
+		return 0, "", false
+	}
+
+	number := v.FieldByName("Number")
+	if !number.IsValid() || number.Kind() != reflect.Uint16 {
+		return 0, "", false
+	}
+
+	state := v.FieldByName("SQLState")
+	if !state.IsValid() || state.Kind() != reflect.Array || state.Len() != 5 || state.Type().Elem().Kind() != reflect.Uint8 {
+		return 0, "", false
+	}
+
+	b := make([]byte, state.Len())
+	for i := range b {
+		b[i] = byte(state.Index(i).Uint())
+	}
+
+	return uint16(number.Uint()), strings.TrimRight(string(b), "\x00"), true
+}
+
+// stringField returns the first non empty string field of the struct err points to.
+func stringField(err error, names ...string) string {
+	v, ok := structOf(err)
+	if !ok {
+		return ""
+	}
+
+	for _, name := range names {
+		if f := v.FieldByName(name); f.IsValid() && f.Kind() == reflect.String && f.String() != "" {
+			return f.String()
+		}
+	}
+	return ""
+}
+
+// structOf returns the struct err is or points to.
+func structOf(err error) (reflect.Value, bool) {
+	v := reflect.Indirect(reflect.ValueOf(err))
+	return v, v.Kind() == reflect.Struct
+}
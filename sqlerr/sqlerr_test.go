@@ -0,0 +1,164 @@
+package sqlerr
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/sloory/cerrors"
+)
+
+// PgError mirrors *pgconn.PgError of github.com/jackc/pgx/v5.
+type PgError struct {
+	Code           string
+	Message        string
+	SchemaName     string
+	TableName      string
+	ColumnName     string
+	ConstraintName string
+}
+
+func (e *PgError) Error() string    { return e.Message + " (SQLSTATE " + e.Code + ")" }
+func (e *PgError) SQLState() string { return e.Code }
+
+// pqError mirrors *pq.Error of github.com/lib/pq.
+type pqError struct {
+	Code       string
+	Table      string
+	Constraint string
+}
+
+func (e *pqError) Error() string    { return "pq: " + e.Code }
+func (e *pqError) SQLState() string { return e.Code }
+
+// MySQLError mirrors *mysql.MySQLError of github.com/go-sql-driver/mysql.
+type MySQLError struct {
+	Number   uint16
+	SQLState [5]byte
+	Message  string
+}
+
+func (e *MySQLError) Error() string { return fmt.Sprintf("Error %d: %s", e.Number, e.Message) }
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		kind      cerrors.Kind
+		retryable bool
+		fields    map[string]any
+	}{
+		{"no rows", fmt.Errorf("get user: %w", sql.ErrNoRows), cerrors.KindNotFound, false, nil},
+		{"bad connection", driver.ErrBadConn, cerrors.KindUnavailable, true, nil},
+		{"connection done", sql.ErrConnDone, cerrors.KindUnavailable, false, nil},
+		{"tx done", sql.ErrTxDone, cerrors.KindInternal, false, nil},
+		{
+			"unique violation",
+			fmt.Errorf("insert user: %w", &PgError{Code: "23505", SchemaName: "public", TableName: "users", ConstraintName: "users_email_key"}),
+			cerrors.KindConflict, false,
+			map[string]any{"sqlstate": "23505", "schema": "public", "table": "users", "constraint": "users_email_key"},
+		},
+		{
+			"not null violation",
+			&PgError{Code: "23502", TableName: "users", ColumnName: "email"},
+			cerrors.KindInvalid, false,
+			map[string]any{"sqlstate": "23502", "table": "users", "column": "email"},
+		},
+		{"serialization failure", &PgError{Code: "40001"}, cerrors.KindConflict, true, map[string]any{"sqlstate": "40001"}},
+		{"connection class", &PgError{Code: "08006"}, cerrors.KindUnavailable, true, map[string]any{"sqlstate": "08006"}},
+		{"unknown code", &PgError{Code: "XX000"}, cerrors.KindInternal, false, map[string]any{"sqlstate": "XX000"}},
+		{
+			"lib/pq",
+			&pqError{Code: "23503", Table: "orders", Constraint: "orders_user_id_fkey"},
+			cerrors.KindInvalid, false,
+			map[string]any{"sqlstate": "23503", "table": "orders", "constraint": "orders_user_id_fkey"},
+		},
+		{
+			"mysql duplicate",
+			fmt.Errorf("insert user: %w", &MySQLError{Number: 1062, SQLState: [5]byte{'2', '3', '0', '0', '0'}}),
+			cerrors.KindConflict, false,
+			map[string]any{"mysql.number": uint16(1062), "sqlstate": "23000"},
+		},
+		{
+			"mysql deadlock",
+			&MySQLError{Number: 1213, SQLState: [5]byte{'4', '0', '0', '0', '1'}},
+			cerrors.KindConflict, true,
+			map[string]any{"mysql.number": uint16(1213), "sqlstate": "40001"},
+		},
+		{"mysql unknown", &MySQLError{Number: 1}, cerrors.KindInternal, false, map[string]any{"mysql.number": uint16(1)}},
+		{
+			"mysql joined",
+			errors.Join(errors.New("rollback"), &MySQLError{Number: 1062}),
+			cerrors.KindConflict, false,
+			map[string]any{"mysql.number": uint16(1062)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Classify(tt.err)
+
+			if !errors.Is(err, tt.err) {
+				t.Errorf("lost original error: %v", err)
+			}
+			if cerrors.KindOf(err) != tt.kind {
+				t.Errorf("unexpected kind: expected %v, got %v", tt.kind, cerrors.KindOf(err))
+			}
+			if cerrors.IsRetryable(err) != tt.retryable {
+				t.Errorf("unexpected retryable: expected %v, got %v", tt.retryable, cerrors.IsRetryable(err))
+			}
+			if !reflect.DeepEqual(tt.fields, cerrors.Fields(err)) {
+				t.Errorf("unexpected fields: expected %v, got %v", tt.fields, cerrors.Fields(err))
+			}
+		})
+	}
+
+	t.Run("nil", func(t *testing.T) {
+		if Classify(nil) != nil {
+			t.Error("not nil error")
+		}
+	})
+
+	t.Run("not database error", func(t *testing.T) {
+		err := errors.New("err")
+		if Classify(err) != err {
+			t.Error("changed error")
+		}
+	})
+
+	t.Run("existing kind", func(t *testing.T) {
+		err := cerrors.WithKind(sql.ErrNoRows, cerrors.KindInvalid)
+		if Classify(err) != err {
+			t.Error("changed error")
+		}
+	})
+}
+
+func TestHook(t *testing.T) {
+	ctx := cerrors.WithEnrichHook(context.Background(), Hook)
+	ctx = cerrors.WithCtxField(ctx, "requestId", 1)
+
+	err := cerrors.Enrich(ctx, &PgError{Code: "40P01", TableName: "orders"})
+
+	if cerrors.KindOf(err) != cerrors.KindConflict || !cerrors.IsRetryable(err) {
+		t.Errorf("unexpected classification: kind %v, retryable %v", cerrors.KindOf(err), cerrors.IsRetryable(err))
+	}
+
+	expected := map[string]any{"requestId": 1, "sqlstate": "40P01", "table": "orders"}
+	if !reflect.DeepEqual(expected, cerrors.Fields(err)) {
+		t.Errorf("unexpected fields: expected %v, got %v", expected, cerrors.Fields(err))
+	}
+
+	if cerrors.Stack(err) == nil {
+		t.Error("expect stack")
+	}
+
+	err = cerrors.Enrich(ctx, fmt.Errorf("insert user: %w", &MySQLError{Number: 1062, SQLState: [5]byte{'2', '3', '0', '0', '0'}}))
+
+	if cerrors.KindOf(err) != cerrors.KindConflict {
+		t.Errorf("unexpected kind of MySQL error: %v", cerrors.KindOf(err))
+	}
+}
@@ -1,7 +1,6 @@
 package cerrors
 
 import (
-	"errors"
 	"fmt"
 	"io"
 	"path"
@@ -18,19 +17,34 @@ type stackTrace interface {
 type withStack struct {
 	cause error
 	stack StackTrace
+	// cfg is the configuration the stack was captured with, used to format it
+	cfg *Config
+	// goroutine is known only with Config.RethrowStacks
+	goroutine uint64
+	rethrown  bool
 }
 
-func newWithStack(err error) error {
+// newWithStack captures the stack starting skip frames above its caller, skipping
+// the frames of cerrors between the caller and the code the stack is captured for.
+func newWithStack(cfg *Config, err error, skip int) error {
 	if err == nil {
 		return nil
 	}
 
-	var stErr stackTrace
-	if errors.As(err, &stErr) {
-		return err
+	if !hasStack(cfg, err) {
+		return &withStack{cause: err, stack: callers(1+skip, cfg), cfg: cfg, goroutine: rethrowGoroutine(cfg)}
 	}
 
-	return &withStack{cause: err, stack: callers(2)} //nolint:gomnd // self-explained
+	if !cfg.RethrowStacks {
+		return joined(err)
+	}
+
+	stack, goroutine := callers(1+skip, cfg), goroutineID()
+	if !isRethrown(err, stack, goroutine) {
+		return joined(err)
+	}
+
+	return &withStack{cause: joined(err), stack: stack, cfg: cfg, goroutine: goroutine, rethrown: true}
 }
 
 // *** Code from https://github.com/pkg/errors/blob/master/stack.go ** //
@@ -41,13 +55,23 @@ func (w *withStack) Error() string          { return w.cause.Error() }
 func (w *withStack) Cause() error           { return w.cause }
 func (w *withStack) Unwrap() error          { return w.cause }
 func (w *withStack) StackTrace() StackTrace { return w.stack }
+func (w *withStack) Callers() []uintptr {
+	pcs := make([]uintptr, len(w.stack))
+	for i, f := range w.stack {
+		pcs[i] = uintptr(f)
+	}
+	return pcs
+}
 
 func (w *withStack) Format(s fmt.State, verb rune) {
 	switch verb {
 	case 'v':
 		if s.Flag('+') {
 			fmt.Fprintf(s, "%+v", w.Cause())
-			w.stack.Format(s, verb)
+			if w.rethrown {
+				io.WriteString(s, "\nrethrown at:")
+			}
+			w.stack.format(s, verb, w.cfg)
 			return
 		}
 		io.WriteString(s, w.Error())
@@ -96,6 +120,19 @@ func (f Frame) name() string {
 	return fn.Name()
 }
 
+// PC returns the program counter for this frame.
+func (f Frame) PC() uintptr { return f.pc() }
+
+// File returns the full path to the file that contains the function for this frame,
+// "unknown" if it is not known.
+func (f Frame) File() string { return f.file() }
+
+// Line returns the line number of source code of the function for this frame.
+func (f Frame) Line() int { return f.line() }
+
+// Function returns the name of the function for this frame, "unknown" if it is not known.
+func (f Frame) Function() string { return f.name() }
+
 // Format formats the frame according to the fmt.Formatter interface.
 func (f Frame) Format(s fmt.State, verb rune) {
 	switch verb {
@@ -124,15 +161,29 @@ type StackTrace []Frame
 //
 // Format accepts flags that alter the printing of some verbs, as follows:
 //
-//	%+v   Prints filename, function, and line number for each Frame in the stack.
+//	%+v   Prints filename, function, and line number for each Frame in the stack
+//	      in the layout of Config.StackFormat, and lines of source around it
+//	      with Config.SourceContext.
 func (st StackTrace) Format(s fmt.State, verb rune) {
+	st.format(s, verb, currentConfig())
+}
+
+func (st StackTrace) format(s fmt.State, verb rune, cfg *Config) {
 	switch verb {
 	case 'v':
 		switch {
 		case s.Flag('+'):
+			around := cfg.SourceContext
 			for _, f := range st {
 				io.WriteString(s, "\n")
-				f.Format(s, verb)
+				if cfg.StackFormat == StackFormatCompact {
+					io.WriteString(s, f.name()+" "+f.file()+":"+strconv.Itoa(f.line()))
+				} else {
+					f.Format(s, verb)
+				}
+				if around > 0 {
+					writeSourceSnippet(s, f, around)
+				}
 			}
 		case s.Flag('#'):
 			fmt.Fprintf(s, "%#v", []Frame(st))
@@ -144,6 +195,22 @@ func (st StackTrace) Format(s fmt.State, verb rune) {
 	}
 }
 
+// Frames returns an iterator over the frames with their indexes,
+// usable as a range-over-func iterator with Go 1.23 and later:
+//
+//	for i, f := range st.Frames() {
+//		...
+//	}
+func (st StackTrace) Frames() func(yield func(int, Frame) bool) {
+	return func(yield func(int, Frame) bool) {
+		for i, f := range st {
+			if !yield(i, f) {
+				return
+			}
+		}
+	}
+}
+
 // formatSlice will format this StackTrace into the given buffer as a slice of
 // Frame, only valid when called with '%s' or '%v'.
 func (st StackTrace) formatSlice(s fmt.State, verb rune) {
@@ -158,14 +225,16 @@ func (st StackTrace) formatSlice(s fmt.State, verb rune) {
 }
 
 // callers mirrors the code in github.com/pkg/errors,
-// but makes the depth customizable.
-func callers(depth int) StackTrace {
-	const numFrames = 32
-	var pcs [numFrames]uintptr
-	n := runtime.Callers(2+depth, pcs[:])
-	f := make([]Frame, n)
+// but makes the depth customizable and applies the configured frames limit and filter.
+func callers(depth int, cfg *Config) StackTrace {
+	pcs := make([]uintptr, cfg.stackDepth())
+	n := runtime.Callers(2+depth, pcs)
+	f := make([]Frame, 0, n)
 	for i := 0; i < n; i++ {
-		f[i] = Frame(pcs[i])
+		if cfg.StackFilter != nil && !cfg.StackFilter(Frame(pcs[i])) {
+			continue
+		}
+		f = append(f, Frame(pcs[i]))
 	}
 	return f
 }
@@ -0,0 +1,91 @@
+package cerrors
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Template defines an error once, like a sentinel error of errors.New, and creates
+// enriched instances of it with New. errors.Is matches every instance with its template.
+//
+//	var ErrUserNotFound = cerrors.Define("user.not_found", cerrors.KindNotFound, "user %{id} not found")
+//
+//	err := ErrUserNotFound.New(ctx, cerrors.F("id", 42)) // user 42 not found
+//	errors.Is(err, ErrUserNotFound)                      // true
+type Template struct {
+	code    string
+	kind    Kind
+	message string
+}
+
+// Define returns a template of errors with the code and the kind. Placeholders %{name}
+// of message are replaced with values of the fields name given to New.
+func Define(code string, kind Kind, message string) *Template {
+	return &Template{code: code, kind: kind, message: message}
+}
+
+func (t *Template) Code() string    { return t.code }
+func (t *Template) Kind() Kind      { return t.kind }
+func (t *Template) Message() string { return t.message }
+
+// Error returns the message without rendered placeholders.
+func (t *Template) Error() string { return t.message }
+
+// Field is a field of an error created from a template.
+type Field struct {
+	Key   string
+	Value any
+}
+
+func F(key string, value any) Field {
+	return Field{Key: key, Value: value}
+}
+
+// New returns an instance of the template with the rendered message, the code, the kind,
+// the fields and everything Enrich attaches from ctx, including the stack of the caller.
+func (t *Template) New(ctx context.Context, fields ...Field) error {
+	cfg := configFrom(ctx)
+	var err error = &templateError{template: t, message: t.render(cfg, fields)}
+
+	if len(fields) > 0 {
+		m := make(map[string]any, len(fields))
+		for _, f := range fields {
+			m[f.Key] = f.Value
+		}
+
+		var fErr withFields
+		fErr, err = newWithFields(err)
+		mergeFields(cfg, fErr, m)
+
+		// the fields given here win over the fields of ctx, as the message shows them
+		ctx = withoutCtxFields(ctx, m)
+	}
+
+	err = newWithCode(err, t.code)
+	err = WithKind(err, t.kind)
+
+	return enrich(ctx, err, 1)
+}
+
+// render replaces placeholders with redacted values of fields.
+func (t *Template) render(cfg *Config, fields []Field) string {
+	if !strings.Contains(t.message, "%{") {
+		return t.message
+	}
+
+	replacements := make([]string, 0, 2*len(fields))
+	for _, f := range fields {
+		replacements = append(replacements, "%{"+f.Key+"}", fmt.Sprint(cfg.redact(f.Key, f.Value)))
+	}
+
+	return strings.NewReplacer(replacements...).Replace(t.message)
+}
+
+type templateError struct {
+	template *Template
+	message  string
+}
+
+func (e *templateError) Error() string        { return e.message }
+func (e *templateError) Is(target error) bool { return target == e.template }
@@ -0,0 +1,92 @@
+package cerrors
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var errTestUserNotFound = Define("user.not_found", KindNotFound, "user %{id} not found in %{db}")
+
+func TestTemplate(t *testing.T) {
+	t.Run("new", func(t *testing.T) {
+		ctx := InComponent(context.Background(), "users")
+		ctx = WithCtxField(ctx, "requestId", 1)
+
+		err := errTestUserNotFound.New(ctx, F("id", 42), F("db", "main"))
+
+		if expected := "user 42 not found in main"; err.Error() != expected {
+			t.Errorf("unexpected message: expected %q, got %q", expected, err.Error())
+		}
+		if Code(err) != "user.not_found" || KindOf(err) != KindNotFound {
+			t.Errorf("unexpected code %q or kind %v", Code(err), KindOf(err))
+		}
+		if !reflect.DeepEqual([]string{"users"}, Components(err)) {
+			t.Errorf("unexpected components: %v", Components(err))
+		}
+		requireFields(t, err, map[string]any{"id": 42, "db": "main", "requestId": 1})
+
+		stack := Stack(err)
+		if len(stack) == 0 || !strings.HasSuffix(stack[0].Function(), "cerrors.TestTemplate.func1") {
+			t.Errorf("unexpected stack: %v", stack)
+		}
+	})
+
+	t.Run("fields of context", func(t *testing.T) {
+		ctx := WithCtxField(context.Background(), "id", 1)
+		ctx = WithCtxField(ctx, "requestId", 2)
+
+		err := errTestUserNotFound.New(ctx, F("id", 42), F("db", "main"))
+
+		if expected := "user 42 not found in main"; err.Error() != expected {
+			t.Errorf("unexpected message: expected %q, got %q", expected, err.Error())
+		}
+		requireFields(t, err, map[string]any{"id": 42, "db": "main", "requestId": 2})
+
+		if CtxFields(ctx)["id"] != 1 {
+			t.Errorf("unexpected context fields: %v", CtxFields(ctx))
+		}
+	})
+
+	t.Run("is", func(t *testing.T) {
+		err := Wrap("get user", errTestUserNotFound.New(context.Background(), F("id", 1)))
+
+		if !errors.Is(err, errTestUserNotFound) {
+			t.Error("expect template to match")
+		}
+
+		other := Define("user.not_found", KindNotFound, "user %{id} not found in %{db}")
+		if errors.Is(err, other) {
+			t.Error("unexpected match of other template")
+		}
+	})
+
+	t.Run("missing placeholders", func(t *testing.T) {
+		err := errTestUserNotFound.New(context.Background())
+
+		if expected := "user %{id} not found in %{db}"; err.Error() != expected {
+			t.Errorf("unexpected message: expected %q, got %q", expected, err.Error())
+		}
+		if Fields(err) != nil {
+			t.Errorf("unexpected fields: %v", Fields(err))
+		}
+	})
+
+	t.Run("redacted", func(t *testing.T) {
+		ctx := WithConfig(context.Background(), Config{Redactor: func(key string, value any) any {
+			if key == "id" {
+				return "***"
+			}
+			return value
+		}})
+
+		err := errTestUserNotFound.New(ctx, F("id", 42), F("db", "main"))
+
+		if expected := "user *** not found in main"; err.Error() != expected {
+			t.Errorf("unexpected message: expected %q, got %q", expected, err.Error())
+		}
+		requireFields(t, err, map[string]any{"id": "***", "db": "main"})
+	})
+}